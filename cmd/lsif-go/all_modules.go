@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/sourcegraph/lsif-go/internal/git"
+	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/output"
+)
+
+// indexAllModules indexes each module under the repository root as if lsif-go had been run
+// in the module's directory. The dump of each module is written into its directory under the
+// base name of the output file, and the version of each module is inferred from the tags of
+// the work tree commit (tags of nested modules are prefixed with the module's path).
+func indexAllModules(outputOptions output.Options) error {
+	moduleRoots, err := gomod.ListModuleRoots(repositoryRoot)
+	if err != nil {
+		return fmt.Errorf("failed to find modules: %v", err)
+	}
+	if len(moduleRoots) == 0 {
+		return fmt.Errorf("no go.mod file found under %s", repositoryRoot)
+	}
+
+	for _, moduleRoot := range moduleRoots {
+		relative, err := filepath.Rel(repositoryRoot, moduleRoot)
+		if err != nil {
+			return err
+		}
+		log.Printf("Indexing module %s", relative)
+
+		moduleVersion, err := git.InferNestedModuleVersion(repositoryRoot, moduleRoot)
+		if err != nil {
+			return fmt.Errorf("failed to infer version of module %s: %v", relative, err)
+		}
+
+		outFile := filepath.Join(moduleRoot, filepath.Base(outFile))
+		if err := indexModule(moduleRoot, moduleRoot, moduleVersion, outFile, outputOptions); err != nil {
+			return fmt.Errorf("module %s: %v", relative, err)
+		}
+	}
+
+	return nil
+}
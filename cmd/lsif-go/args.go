@@ -3,12 +3,18 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/alecthomas/kingpin"
+	"github.com/sourcegraph/lsif-go/internal/compression"
 	"github.com/sourcegraph/lsif-go/internal/git"
+	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/indexer"
+	"github.com/sourcegraph/lsif-go/internal/query"
 	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/analysis"
 )
 
 var app = kingpin.New(
@@ -17,18 +23,72 @@ var app = kingpin.New(
 ).Version(version + ", protocol version " + protocol.Version)
 
 var (
-	outFile               string
-	projectRoot           string
-	moduleRoot            string
-	repositoryRoot        string
-	repositoryRemote      string
-	moduleVersion         string
-	verbosity             int
-	noOutput              bool
-	animation             bool
-	depBatchSize          int
-	enableApiDocs         bool
-	enableImplementations bool
+	indexCommand    = app.Command("index", "Index the Go module in the current directory (default).").Default()
+	validateCommand = app.Command("validate", "Check the graph invariants of an LSIF dump.")
+	serveCommand    = app.Command("serve", "Run a language server over stdio that answers requests from an LSIF dump.")
+	queryCommand    = app.Command("query", "Print what an LSIF dump knows about a position.")
+	diffCommand     = app.Command("diff", "Compare the definitions, references, hovers, and monikers of two LSIF dumps.")
+
+	// queryCommands maps each query subcommand to the kind of query it runs.
+	queryCommands = map[*kingpin.CmdClause]query.Kind{
+		queryCommand.Command("definition", "Print the definitions of the symbol at path/to/file.go:LINE:COL."): query.Definition,
+		queryCommand.Command("references", "Print the references of the symbol at path/to/file.go:LINE:COL."):  query.References,
+		queryCommand.Command("hover", "Print the hover text of the symbol at path/to/file.go:LINE:COL."):       query.Hover,
+		queryCommand.Command("monikers", "Print the monikers of the symbol at path/to/file.go:LINE:COL."):      query.Monikers,
+	}
+)
+
+var (
+	outFile                        string
+	outFormat                      string
+	compress                       string
+	projectRoot                    string
+	moduleRoot                     string
+	repositoryRoot                 string
+	repositoryRemote               string
+	moduleVersion                  string
+	verbosity                      int
+	noOutput                       bool
+	animation                      bool
+	depBatchSize                   int
+	deterministic                  bool
+	enableApiDocs                  bool
+	enableImplementations          bool
+	enableCallHierarchy            bool
+	enableInterfaceImplementations bool
+	previousDumpFile               string
+	baseRevision                   string
+	workspace                      bool
+	allModules                     bool
+	buildConfigs                   string
+	buildTags                      string
+	importMapFile                  string
+	allowNetwork                   bool
+	analyzers                      string
+
+	// parsed from buildConfigs and buildTags by sanitizeBuild
+	buildConfigList []indexer.BuildConfig
+	buildTagList    []string
+
+	// parsed from importMapFile and the config file by sanitizeImportMap
+	importMap map[string]string
+
+	// parsed from analyzers by sanitizeAnalyzers
+	analyzerList []*analysis.Analyzer
+
+	// validate command
+	validateDumpFile string
+
+	// serve command
+	serveDumpFile string
+
+	// query command
+	queryDumpFile string
+	queryPosition string
+
+	// diff command
+	diffOldDumpFile string
+	diffNewDumpFile string
 )
 
 func init() {
@@ -36,49 +96,105 @@ func init() {
 	app.VersionFlag.Short('V')
 
 	// Outfile options
-	app.Flag("output", "The output file.").Short('o').Default("dump.lsif").StringVar(&outFile)
+	app.Flag("output", "The output file, or - for stdout (default dump.lsif, or index.scip when --format=scip).").Short('o').StringVar(&outFile)
+	app.Flag("format", "The output format (lsif or scip).").Default("lsif").EnumVar(&outFormat, "lsif", "scip")
+	app.Flag("compress", "Compress the output (none, gzip, or zstd). Inferred from a .gz or .zst output file extension by default.").EnumVar(&compress, "none", "gzip", "zstd")
 
 	// Path options (inferred by presence of go.mod; git)
 	app.Flag("project-root", "Specifies the directory to index.").Default(".").StringVar(&projectRoot)
 	app.Flag("module-root", "Specifies the directory containing the go.mod file.").Default(defaultModuleRoot.Value()).StringVar(&moduleRoot)
+	app.Flag("workspace", "Index every module of the go.work file in the project root, each as its own project.").Default("false").BoolVar(&workspace)
+	app.Flag("all-modules", "Index every module under the repository root, writing a dump named after --output into each module directory.").Default("false").BoolVar(&allModules)
 	app.Flag("repository-root", "Specifies the top-level directory of the git repository.").Default(defaultRepositoryRoot.Value()).StringVar(&repositoryRoot)
 
 	// Repository remote and tag options (inferred by git)
 	app.Flag("repository-remote", "Specifies the canonical name of the repository remote.").Default(defaultRepositoryRemote.Value()).StringVar(&repositoryRemote)
 	app.Flag("module-version", "Specifies the version of the module defined by module-root.").Default(defaultModuleVersion.Value()).StringVar(&moduleVersion)
 
+	// Module resolution options
+	app.Flag("import-map", "A JSON file mapping import path prefixes to repository URLs (e.g. {\"go.company.com/x\": \"https://git.company.com/x\"}). Overrides the import-map of .lsif-go.yaml in the repository root.").ExistingFileVar(&importMapFile)
+	app.Flag("allow-network", "Resolve the repositories of import paths that cannot be resolved offline by fetching their go-get meta tags.").Default("false").BoolVar(&allowNetwork)
+
 	// Verbosity options
 	app.Flag("quiet", "Do not output to stdout or stderr.").Short('q').Default("false").BoolVar(&noOutput)
 	app.Flag("verbose", "Output debug logs.").Short('v').CounterVar(&verbosity)
 	app.Flag("animation", "Do not animate output.").Default("false").BoolVar(&animation)
 
 	app.Flag("dep-batch-size", "How many dependencies to load at once to limit memory usage (e.g. 100). 0 means load all at once.").Default("0").IntVar(&depBatchSize)
+	app.Flag("deterministic", "Produce byte-identical output for identical input. This disables concurrent indexing.").Default("false").BoolVar(&deterministic)
+
+	// Build options
+	app.Flag("build-configs", "Comma-separated GOOS/GOARCH pairs to load the project for (e.g. linux/amd64,windows/amd64). Defaults to the host platform.").StringVar(&buildConfigs)
+	app.Flag("tags", "Comma-separated build tags to satisfy while loading packages.").StringVar(&buildTags)
+
+	// Analysis options
+	app.Flag("analyzers", "Comma-separated analyzers to run over the project packages, whose findings are emitted as diagnostics (vet, or the name of a vet analyzer such as printf).").StringVar(&analyzers)
+
+	// Incremental indexing options
+	app.Flag("previous-dump", "An earlier dump of the project to copy the data of unchanged packages from. Requires --base-revision.").ExistingFileVar(&previousDumpFile)
+	app.Flag("base-revision", "The git revision the previous dump was generated from.").StringVar(&baseRevision)
 
 	// Feature flags
 	app.Flag("enable-api-docs", "Enable Sourcegraph API Doc generation").Default("false").BoolVar(&enableApiDocs)
 	app.Flag("enable-implementations", "Enable textDocument/implementation generation").Default("true").BoolVar(&enableImplementations)
+	app.Flag("enable-call-hierarchy", "Enable callHierarchy/call generation, linking each function to the functions it calls").Default("false").BoolVar(&enableCallHierarchy)
+	app.Flag("enable-interface-implementations", "Enable textDocument/implementation generation between interfaces, linking each interface to the interfaces whose methods it has. Interfaces of dependencies are not linked to each other").Default("false").BoolVar(&enableInterfaceImplementations)
+
+	validateCommand.Arg("dump", "The dump file to validate.").Required().ExistingFileVar(&validateDumpFile)
+	serveCommand.Flag("dump", "The dump file to serve.").Required().ExistingFileVar(&serveDumpFile)
+	queryCommand.Flag("dump", "The dump file to query.").Required().ExistingFileVar(&queryDumpFile)
+	for command := range queryCommands {
+		command.Arg("position", "The position to query, as path/to/file.go:LINE:COL (1-based).").Required().StringVar(&queryPosition)
+	}
+	diffCommand.Arg("old", "The dump to compare against.").Required().ExistingFileVar(&diffOldDumpFile)
+	diffCommand.Arg("new", "The dump to compare.").Required().ExistingFileVar(&diffNewDumpFile)
 }
 
-func parseArgs(args []string) (err error) {
-	if _, err := app.Parse(args); err != nil {
-		return fmt.Errorf("failed to parse args: %v", err)
+func parseArgs(args []string) (command string, err error) {
+	command, err = app.Parse(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if command != indexCommand.FullCommand() {
+		return command, nil
 	}
 
-	sanitizers := []func() error{sanitizeProjectRoot, sanitizeModuleRoot, sanitizeRepositoryRoot}
-	validators := []func() error{validatePaths}
+	sanitizers := []func() error{sanitizeOutFile, sanitizeCompress, sanitizeProjectRoot, sanitizeModuleRoot, sanitizeRepositoryRoot, sanitizeBuild, sanitizeImportMap, sanitizeAnalyzers}
+	validators := []func() error{validatePaths, validateIncremental, validateAllModules}
 
 	for _, f := range append(sanitizers, validators...) {
 		if err := f(); err != nil {
-			return fmt.Errorf("failed to parse args: %v", err)
+			return "", fmt.Errorf("failed to parse args: %v", err)
 		}
 	}
 
-	return nil
+	return command, nil
 }
 
 //
 // Sanitizers
 
+func sanitizeOutFile() error {
+	if outFile == "" {
+		if outFormat == "scip" {
+			outFile = "index.scip"
+		} else {
+			outFile = "dump.lsif"
+		}
+	}
+
+	return nil
+}
+
+func sanitizeCompress() error {
+	if compress == "" {
+		compress = string(compression.FromPath(outFile))
+	}
+
+	return nil
+}
+
 func sanitizeProjectRoot() (err error) {
 	projectRoot, err = filepath.Abs(projectRoot)
 	if err != nil {
@@ -106,6 +222,55 @@ func sanitizeRepositoryRoot() (err error) {
 	return nil
 }
 
+func sanitizeBuild() (err error) {
+	if buildConfigList, err = indexer.ParseBuildConfigs(buildConfigs); err != nil {
+		return err
+	}
+
+	for _, tag := range strings.Split(buildTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			buildTagList = append(buildTagList, tag)
+		}
+	}
+
+	return nil
+}
+
+func sanitizeImportMap() (err error) {
+	importMap = map[string]string{}
+
+	configFile := filepath.Join(repositoryRoot, gomod.ConfigFilename)
+	if _, err := os.Stat(configFile); err == nil {
+		rules, err := gomod.ReadConfigImportMap(configFile)
+		if err != nil {
+			return fmt.Errorf("read config: %v", err)
+		}
+
+		for prefix, repo := range rules {
+			importMap[prefix] = repo
+		}
+	}
+
+	// Rules of the import map file take precedence over those of the config file
+	if importMapFile != "" {
+		rules, err := gomod.ReadImportMap(importMapFile)
+		if err != nil {
+			return fmt.Errorf("read import map: %v", err)
+		}
+
+		for prefix, repo := range rules {
+			importMap[prefix] = repo
+		}
+	}
+
+	return nil
+}
+
+func sanitizeAnalyzers() (err error) {
+	analyzerList, err = indexer.ParseAnalyzers(analyzers)
+	return err
+}
+
 //
 // Validators
 
@@ -121,6 +286,36 @@ func validatePaths() error {
 	return nil
 }
 
+func validateIncremental() error {
+	if (previousDumpFile == "") != (baseRevision == "") {
+		return errors.New("--previous-dump and --base-revision must be supplied together")
+	}
+
+	if previousDumpFile != "" && outFormat == "scip" {
+		// The kinds of the symbols copied from the previous dump are unknown
+		return errors.New("--previous-dump cannot be combined with --format=scip")
+	}
+
+	return nil
+}
+
+func validateAllModules() error {
+	if !allModules {
+		return nil
+	}
+
+	switch {
+	case workspace:
+		return errors.New("--all-modules cannot be combined with --workspace")
+	case previousDumpFile != "":
+		return errors.New("--all-modules cannot be combined with --previous-dump")
+	case outFile == "-":
+		return errors.New("--all-modules cannot write to stdout")
+	}
+
+	return nil
+}
+
 //
 // Defaults
 
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sourcegraph/lsif-go/internal/diff"
+	"github.com/sourcegraph/lsif-go/internal/dump"
+)
+
+// diffDumps prints how the code navigation of the new dump differs from the old dump.
+func diffDumps(oldFilename, newFilename string) error {
+	graphs := make([]*dump.Graph, 0, 2)
+	for _, filename := range []string{oldFilename, newFilename} {
+		elements, err := dump.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read dump %s: %v", filename, err)
+		}
+
+		graphs = append(graphs, dump.NewGraph(elements))
+	}
+
+	changes, err := diff.Diff(graphs[0], graphs[1])
+	if err != nil {
+		return fmt.Errorf("failed to compare dumps: %v", err)
+	}
+
+	diff.Write(changes, os.Stdout)
+	fmt.Printf("%d changes.\n", len(changes))
+	return nil
+}
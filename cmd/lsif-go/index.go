@@ -2,24 +2,41 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/sourcegraph/lsif-go/internal/compression"
 	"github.com/sourcegraph/lsif-go/internal/gomod"
 	"github.com/sourcegraph/lsif-go/internal/indexer"
 	"github.com/sourcegraph/lsif-go/internal/output"
+	"github.com/sourcegraph/lsif-go/internal/scip"
 	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
 )
 
-func writeIndex(repositoryRoot, repositoryRemote, projectRoot, moduleName, moduleVersion string, dependencies map[string]gomod.GoModule, projectDependencies []string, outFile string, outputOptions output.Options, generationOptions indexer.GenerationOptions) error {
+func writeIndex(repositoryRoot, repositoryRemote, projectRoot, moduleName, moduleVersion string, dependencies map[string]gomod.GoModule, projectDependencies []string, outFile, outFormat, compress string, outputOptions output.Options, generationOptions indexer.GenerationOptions) error {
 	start := time.Now()
 
-	out, err := os.Create(outFile)
+	out := os.Stdout
+	closeOut := func() error { return nil }
+	if outFile != "-" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return fmt.Errorf("failed to create dump file: %v", err)
+		}
+		closeOut = closeOnce(f)
+		defer closeOut()
+		out = f
+	}
+
+	compressedOut, err := compression.NewWriter(out, compression.Algorithm(compress))
 	if err != nil {
-		return fmt.Errorf("failed to create dump file: %v", err)
+		return fmt.Errorf("failed to create compressor: %v", err)
 	}
-	defer out.Close()
+	closeCompressedOut := closeOnce(compressedOut)
+	defer closeCompressedOut()
 
 	toolInfo := protocol.ToolInfo{
 		Name:    "lsif-go",
@@ -27,6 +44,15 @@ func writeIndex(repositoryRoot, repositoryRemote, projectRoot, moduleName, modul
 		Args:    os.Args[1:],
 	}
 
+	var jsonWriter writer.JSONWriter
+	if outFormat == "scip" {
+		// The SCIP writer collects the LSIF graph emitted by the indexer
+		// and converts it into a SCIP index once the indexer flushes it.
+		jsonWriter = scip.NewWriter(compressedOut, repositoryRoot, toolInfo)
+	} else {
+		jsonWriter = writer.NewJSONWriter(compressedOut)
+	}
+
 	packageDataCache := indexer.NewPackageDataCache()
 
 	// TODO(efritz) - With cgo enabled, the indexer cannot handle packages
@@ -41,7 +67,7 @@ func writeIndex(repositoryRoot, repositoryRemote, projectRoot, moduleName, modul
 		moduleVersion,
 		dependencies,
 		projectDependencies,
-		writer.NewJSONWriter(out),
+		indexer.NewJSONEmitter(jsonWriter),
 		packageDataCache,
 		outputOptions,
 		generationOptions,
@@ -51,13 +77,38 @@ func writeIndex(repositoryRoot, repositoryRemote, projectRoot, moduleName, modul
 		return err
 	}
 
+	// Close the compressor and the file explicitly so that failures to write
+	// the tail of the dump are reported rather than silently truncating it.
+	if err := closeCompressedOut(); err != nil {
+		return fmt.Errorf("failed to compress dump file: %v", err)
+	}
+	if err := closeOut(); err != nil {
+		return fmt.Errorf("failed to close dump file: %v", err)
+	}
+
 	if isVerbose() {
 		displayStats(indexer.Stats(), packageDataCache.Stats(), start)
 	}
+	if getVerbosity() != output.NoOutput {
+		displayPackageErrors(indexer.PackageErrors(), isVerbose())
+	}
 
 	return nil
 }
 
+// closeOnce returns a function that closes the given closer on its first call and returns the
+// result of that first call on every subsequent call. This allows a deferred close on error paths
+// alongside an explicit close whose error is reported.
+func closeOnce(c io.Closer) func() error {
+	var once sync.Once
+	var err error
+
+	return func() error {
+		once.Do(func() { err = c.Close() })
+		return err
+	}
+}
+
 var verbosityLevels = map[int]output.Verbosity{
 	0: output.DefaultOutput,
 	1: output.VerboseOutput,
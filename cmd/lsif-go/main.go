@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/sourcegraph/lsif-go/internal/dump"
 	"github.com/sourcegraph/lsif-go/internal/git"
 	"github.com/sourcegraph/lsif-go/internal/gomod"
 	"github.com/sourcegraph/lsif-go/internal/indexer"
@@ -25,10 +26,26 @@ func main() {
 }
 
 func mainErr() (err error) {
-	if err := parseArgs(os.Args[1:]); err != nil {
+	command, err := parseArgs(os.Args[1:])
+	if err != nil {
 		return err
 	}
 
+	switch command {
+	case validateCommand.FullCommand():
+		return validateDump(validateDumpFile)
+	case serveCommand.FullCommand():
+		return serveDump(serveDumpFile)
+	case diffCommand.FullCommand():
+		return diffDumps(diffOldDumpFile, diffNewDumpFile)
+	}
+
+	for clause, kind := range queryCommands {
+		if clause.FullCommand() == command {
+			return queryDump(queryDumpFile, kind, queryPosition)
+		}
+	}
+
 	if !git.Check(moduleRoot) {
 		return fmt.Errorf("module root is not a git repository")
 	}
@@ -46,24 +63,68 @@ func mainErr() (err error) {
 		}
 	}()
 
+	if outFile == "-" {
+		// The dump is streamed to stdout, so all other output has to get out of its way.
+		// Animations are drawn directly to stdout and are disabled altogether.
+		log.SetOutput(os.Stderr)
+		output.SetOutput(os.Stderr)
+		animation = false
+	}
+
 	outputOptions := output.Options{
 		Verbosity:      getVerbosity(),
 		ShowAnimations: animation,
 	}
 
-	moduleName, isStdLib, err := gomod.ModuleName(moduleRoot, repositoryRemote, outputOptions)
-	if err != nil {
-		return fmt.Errorf("failed to infer module name: %v", err)
+	if allModules {
+		return indexAllModules(outputOptions)
 	}
 
-	dependencies, err := gomod.ListDependencies(moduleRoot, moduleName, moduleVersion, outputOptions)
+	return indexModule(moduleRoot, projectRoot, moduleVersion, outFile, outputOptions)
+}
+
+// indexModule indexes the packages of the given project root, which belong to the module
+// declared in the given module root, and writes the dump to the given file.
+func indexModule(moduleRoot, projectRoot, moduleVersion, outFile string, outputOptions output.Options) (err error) {
+	var (
+		moduleName       string
+		isStdLib         bool
+		workspaceModules []gomod.WorkspaceModule
+		dependencyRoot   = moduleRoot
+		projectPatterns  []string
+		resolver         = gomod.NewResolver(moduleRoot, importMap, allowNetwork)
+	)
+	if workspace {
+		workspaceModules, err = gomod.ListWorkspaceModules(projectRoot, repositoryRemote, resolver, outputOptions)
+		if err != nil {
+			return fmt.Errorf("failed to list workspace modules: %v", err)
+		}
+
+		// Local replace directives are resolved relative to the module at the root of the
+		// workspace, if there is one
+		for _, module := range workspaceModules {
+			if module.Dir == projectRoot {
+				moduleName = module.Name
+			}
+		}
+
+		dependencyRoot = projectRoot
+		projectPatterns = gomod.WorkspacePatterns(projectRoot, workspaceModules)
+	} else {
+		moduleName, isStdLib, err = gomod.ModuleName(moduleRoot, repositoryRemote, resolver, outputOptions)
+		if err != nil {
+			return fmt.Errorf("failed to infer module name: %v", err)
+		}
+	}
+
+	dependencies, err := gomod.ListDependencies(dependencyRoot, moduleName, moduleVersion, resolver, outputOptions)
 	if err != nil {
 		return fmt.Errorf("failed to list dependencies: %v", err)
 	}
 
 	var projectDependencies []string
 	if !isStdLib {
-		projectDependencies, err = gomod.ListProjectDependencies(moduleRoot)
+		projectDependencies, err = gomod.ListProjectDependencies(dependencyRoot, projectPatterns...)
 		if err != nil {
 			return fmt.Errorf("failed to list project dependencies: %v", err)
 		}
@@ -74,7 +135,33 @@ func mainErr() (err error) {
 		return fmt.Errorf("API Docs are no longer supported. To fix this problem, remove the -enable-api-docs flag.")
 	}
 	generationOptions.EnableImplementations = enableImplementations
+	generationOptions.EnableCallHierarchy = enableCallHierarchy
+	generationOptions.EnableInterfaceImplementations = enableInterfaceImplementations
 	generationOptions.DepBatchSize = depBatchSize
+	generationOptions.Deterministic = deterministic
+	generationOptions.EnableUnexportedMonikers = outFormat == "scip"
+	generationOptions.EnableMonikerSymbolKinds = outFormat == "scip"
+	generationOptions.WorkspaceModules = workspaceModules
+	generationOptions.BuildConfigs = buildConfigList
+	generationOptions.BuildTags = buildTagList
+	generationOptions.Analyzers = analyzerList
+
+	if previousDumpFile != "" {
+		elements, err := dump.ReadFile(previousDumpFile)
+		if err != nil {
+			return fmt.Errorf("failed to read previous dump: %v", err)
+		}
+
+		changedFiles, err := git.ChangedFiles(repositoryRoot, baseRevision)
+		if err != nil {
+			return fmt.Errorf("failed to list changed files: %v", err)
+		}
+
+		generationOptions.Previous = &indexer.PreviousDump{
+			Elements:     elements,
+			ChangedFiles: changedFiles,
+		}
+	}
 
 	if err := writeIndex(
 		repositoryRoot,
@@ -85,6 +172,8 @@ func mainErr() (err error) {
 		dependencies,
 		projectDependencies,
 		outFile,
+		outFormat,
+		compress,
 		outputOptions,
 		generationOptions,
 	); err != nil {
@@ -25,7 +25,7 @@ var toplevel = newCachedString(func() string {
 })
 
 func searchForGoMod(path, repositoryRoot string) string {
-	for ; !strings.HasPrefix(path, repositoryRoot); path = filepath.Dir(path) {
+	for ; strings.HasPrefix(path, repositoryRoot); path = filepath.Dir(path) {
 		_, err := os.Stat(filepath.Join(path, "go.mod"))
 		if err == nil {
 			return rel(path)
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/lsif-go/internal/query"
+)
+
+// queryDump prints the result of the given query for a position in the given dump.
+func queryDump(filename string, kind query.Kind, position string) error {
+	elements, err := dump.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read dump: %v", err)
+	}
+
+	return query.Run(dump.NewGraph(elements), kind, position, os.Stdout)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/lsif-go/internal/lsp"
+)
+
+// serveDump runs a language server over stdin and stdout that answers requests from
+// the given dump. Stdout is reserved for the protocol, so nothing else is printed.
+func serveDump(filename string) error {
+	elements, err := dump.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read dump: %v", err)
+	}
+
+	return lsp.NewServer(dump.NewGraph(elements)).Serve(os.Stdin, os.Stdout)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -29,9 +30,32 @@ func displayStats(indexerStats indexer.IndexerStats, packageDataCacheStats index
 		}
 	}
 
-	fmt.Printf("\nStats:\n")
+	fmt.Fprintf(log.Writer(), "\nStats:\n")
 
 	for _, stat := range stats {
-		fmt.Printf("\t%s: %s%s\n", stat.name, strings.Repeat(" ", n-len(stat.name)), stat.value)
+		fmt.Fprintf(log.Writer(), "\t%s: %s%s\n", stat.name, strings.Repeat(" ", n-len(stat.name)), stat.value)
+	}
+}
+
+// displayPackageErrors prints the project packages that failed to load or type check, as the
+// index of such packages may be incomplete. All errors are printed if verbose is true, and only
+// the first error of each package otherwise.
+func displayPackageErrors(packageErrors []indexer.PackageErrors, verbose bool) {
+	if len(packageErrors) == 0 {
+		return
+	}
+
+	fmt.Fprintf(log.Writer(), "\n%d packages had errors and may be indexed incompletely:\n", len(packageErrors))
+
+	for _, p := range packageErrors {
+		fmt.Fprintf(log.Writer(), "\t%s: %d errors\n", p.PkgPath, len(p.Errors))
+
+		errs := p.Errors
+		if !verbose {
+			errs = errs[:1]
+		}
+		for _, err := range errs {
+			fmt.Fprintf(log.Writer(), "\t\t%s\n", err)
+		}
 	}
 }
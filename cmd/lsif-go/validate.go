@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/lsif-go/internal/validation"
+)
+
+// validateDump reads the given dump and prints every violated graph invariant.
+// An error is returned if the dump cannot be read or is invalid.
+func validateDump(filename string) error {
+	elements, err := dump.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read dump: %v", err)
+	}
+
+	errs := validation.Validate(elements)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s is invalid: found %d errors in %d elements", filename, len(errs), len(elements))
+	}
+
+	fmt.Printf("%s is valid (%d elements).\n", filename, len(elements))
+	return nil
+}
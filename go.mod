@@ -9,11 +9,13 @@ require (
 	github.com/google/go-cmp v0.5.6
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/hexops/autogold v1.3.0
+	github.com/klauspost/compress v1.15.15
 	github.com/pkg/errors v0.9.1
 	github.com/slimsag/godocmd v0.0.0-20161025000126-a1005ad29fe3
 	github.com/sourcegraph/lsif-static-doc v0.0.0-20210831232443-e74f711cdf06
 	github.com/sourcegraph/sourcegraph/lib v0.0.0-20210914223954-cff3e4aaa732
 	golang.org/x/tools v0.1.3
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
 require (
@@ -0,0 +1,86 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithm identifies the compression applied to a dump.
+type Algorithm string
+
+const (
+	None Algorithm = "none"
+	Gzip Algorithm = "gzip"
+	Zstd Algorithm = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// FromPath infers the compression algorithm from the extension of the given
+// output path (e.g. dump.lsif.gz or dump.lsif.zst).
+func FromPath(path string) Algorithm {
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		return Gzip
+	case strings.HasSuffix(path, ".zst"):
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// NewWriter wraps the given writer so that everything written to it is compressed with
+// the given algorithm. The returned writer must be closed to flush the end of the stream;
+// closing it does not close the underlying writer.
+func NewWriter(w io.Writer, algorithm Algorithm) (io.WriteCloser, error) {
+	switch algorithm {
+	case None, "":
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+// NewReader wraps the given reader so that a gzip or zstd compressed stream is transparently
+// decompressed. The compression algorithm is detected from the leading bytes of the stream,
+// and uncompressed streams are returned as-is.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(header, zstdMagic):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
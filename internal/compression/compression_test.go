@@ -0,0 +1,75 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected Algorithm
+	}{
+		{path: "dump.lsif", expected: None},
+		{path: "dump.lsif.gz", expected: Gzip},
+		{path: "/tmp/out/dump.lsif.zst", expected: Zstd},
+		{path: "index.scip.gz", expected: Gzip},
+		{path: "dump.gzip", expected: None},
+	}
+
+	for _, testCase := range testCases {
+		if actual := FromPath(testCase.path); actual != testCase.expected {
+			t.Errorf("unexpected algorithm for %q. want=%q have=%q", testCase.path, testCase.expected, actual)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	contents, err := os.ReadFile(filepath.Join("..", "testdata", "fixtures", "main.go"))
+	if err != nil {
+		t.Fatalf("unexpected error reading fixture: %s", err)
+	}
+
+	for _, algorithm := range []Algorithm{None, Gzip, Zstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, algorithm)
+			if err != nil {
+				t.Fatalf("unexpected error creating writer: %s", err)
+			}
+			if _, err := w.Write(contents); err != nil {
+				t.Fatalf("unexpected error writing: %s", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("unexpected error closing writer: %s", err)
+			}
+
+			if algorithm != None && bytes.Equal(buf.Bytes(), contents) {
+				t.Fatalf("expected output to be compressed")
+			}
+
+			r, err := NewReader(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error creating reader: %s", err)
+			}
+			defer r.Close()
+
+			decompressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("unexpected error reading: %s", err)
+			}
+			if !bytes.Equal(decompressed, contents) {
+				t.Errorf("unexpected round-trip contents. want=%q have=%q", contents, decompressed)
+			}
+		})
+	}
+}
+
+func TestNewWriterUnknownAlgorithm(t *testing.T) {
+	if _, err := NewWriter(io.Discard, "lz4"); err == nil {
+		t.Fatalf("expected error for unknown algorithm")
+	}
+}
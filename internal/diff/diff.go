@@ -0,0 +1,237 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// Aspects of a range that are compared between dumps.
+const (
+	Definitions = "definitions"
+	References  = "references"
+	Hover       = "hover"
+	Monikers    = "monikers"
+)
+
+// Kinds of changes to an aspect of a range.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change describes how one aspect of a range differs between two dumps.
+type Change struct {
+	Path   string // document path relative to the project root
+	Range  protocol.RangeData
+	Aspect string
+	Kind   string
+	Old    []string
+	New    []string
+}
+
+// key identifies a range independently of the identifiers assigned by the indexer.
+type key struct {
+	path      string
+	rangeData protocol.RangeData
+}
+
+// facts are the comparable aspects of a range, rendered as sorted strings.
+type facts map[string][]string
+
+// Diff compares the given dumps range by range, where ranges are matched by document path
+// (relative to the project root of each dump) and position. Locations are likewise compared
+// by relative path, so dumps of the same code indexed from different checkouts compare equal.
+func Diff(before, after *dump.Graph) ([]Change, error) {
+	oldFacts, err := collect(before)
+	if err != nil {
+		return nil, err
+	}
+	newFacts, err := collect(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]key, 0, len(oldFacts)+len(newFacts))
+	for k := range oldFacts {
+		keys = append(keys, k)
+	}
+	for k := range newFacts {
+		if _, ok := oldFacts[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		if keys[i].rangeData.Start != keys[j].rangeData.Start {
+			return comparePos(keys[i].rangeData.Start, keys[j].rangeData.Start) < 0
+		}
+
+		return comparePos(keys[i].rangeData.End, keys[j].rangeData.End) < 0
+	})
+
+	var changes []Change
+	for _, k := range keys {
+		for _, aspect := range []string{Definitions, References, Hover, Monikers} {
+			oldValues, newValues := oldFacts[k][aspect], newFacts[k][aspect]
+
+			var kind string
+			switch {
+			case len(oldValues) == 0 && len(newValues) == 0:
+				continue
+			case len(oldValues) == 0:
+				kind = Added
+			case len(newValues) == 0:
+				kind = Removed
+			case !equal(oldValues, newValues):
+				kind = Changed
+			default:
+				continue
+			}
+
+			changes = append(changes, Change{
+				Path:   k.path,
+				Range:  k.rangeData,
+				Aspect: aspect,
+				Kind:   kind,
+				Old:    oldValues,
+				New:    newValues,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// collect renders the comparable aspects of every range of the given dump. If a dump has
+// several ranges at the same position, the first one (by position order) is used.
+func collect(g *dump.Graph) (map[key]facts, error) {
+	rangeFacts := map[key]facts{}
+	for _, uri := range g.Documents() {
+		path := g.RelativePath(uri)
+
+		for _, info := range g.Ranges(uri) {
+			k := key{path: path, rangeData: info.Range}
+			if _, ok := rangeFacts[k]; ok {
+				continue
+			}
+
+			f := facts{
+				Definitions: formatLocations(g, info.Definitions),
+				References:  formatLocations(g, info.References),
+				Monikers:    formatMonikers(info.Monikers),
+			}
+			if info.Hover != nil {
+				text, err := dump.HoverText(info.Hover)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%s: %v", path, formatRange(info.Range), err)
+				}
+				if text != "" {
+					f[Hover] = []string{text}
+				}
+			}
+
+			rangeFacts[k] = f
+		}
+	}
+
+	return rangeFacts, nil
+}
+
+func formatLocations(g *dump.Graph, locations []dump.Location) []string {
+	values := make([]string, 0, len(locations))
+	for _, l := range locations {
+		values = append(values, g.RelativePath(l.URI)+":"+formatRange(l.Range))
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+func formatMonikers(monikers []dump.Moniker) []string {
+	values := make([]string, 0, len(monikers))
+	for _, m := range monikers {
+		value := fmt.Sprintf("%s %s %s", m.Kind, m.Scheme, m.Identifier)
+		if m.PackageName != "" {
+			value += fmt.Sprintf(" (%s@%s)", m.PackageName, m.PackageVersion)
+		}
+
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// formatRange renders the given range as 1-based LINE:COL-LINE:COL.
+func formatRange(r protocol.RangeData) string {
+	return fmt.Sprintf("%d:%d-%d:%d", r.Start.Line+1, r.Start.Character+1, r.End.Line+1, r.End.Character+1)
+}
+
+// Write prints the given changes in a unified-diff-like format: a header line per change
+// followed by the removed (-) and added (+) values.
+func Write(changes []Change, w io.Writer) {
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s %s %s:%s\n", change.Kind, change.Aspect, change.Path, formatRange(change.Range))
+
+		for _, value := range difference(change.Old, change.New) {
+			writeValue(w, "-", value)
+		}
+		for _, value := range difference(change.New, change.Old) {
+			writeValue(w, "+", value)
+		}
+	}
+}
+
+func writeValue(w io.Writer, prefix, value string) {
+	for _, line := range strings.Split(value, "\n") {
+		fmt.Fprintf(w, "  %s %s\n", prefix, line)
+	}
+}
+
+// difference returns the values of a that are not in b.
+func difference(a, b []string) []string {
+	set := make(map[string]struct{}, len(b))
+	for _, value := range b {
+		set[value] = struct{}{}
+	}
+
+	var values []string
+	for _, value := range a {
+		if _, ok := set[value]; !ok {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func comparePos(a, b protocol.Pos) int {
+	if a.Line != b.Line {
+		return a.Line - b.Line
+	}
+
+	return a.Character - b.Character
+}
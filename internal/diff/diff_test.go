@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+type options struct {
+	root          string
+	hover         string
+	definitionAt  protocol.Pos
+	withMoniker   bool
+	withReference bool
+}
+
+// emitGraph emits a dump of main.go defining Foo and referencing it at 6:1, and reads it back.
+func emitGraph(t *testing.T, o options) *dump.Graph {
+	var buf bytes.Buffer
+	e := writer.NewEmitter(writer.NewJSONWriter(&buf))
+
+	e.EmitMetaData("file://"+o.root, protocol.ToolInfo{Name: "lsif-go", Version: "dev"})
+	documentID := e.EmitDocument("go", o.root+"/main.go")
+	definitionRangeID := e.EmitRange(o.definitionAt, protocol.Pos{Line: o.definitionAt.Line, Character: o.definitionAt.Character + 3})
+	rangeIDs := []uint64{definitionRangeID}
+
+	resultSetID := e.EmitResultSet()
+	_ = e.EmitNext(definitionRangeID, resultSetID)
+	definitionResultID := e.EmitDefinitionResult()
+	_ = e.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+	_ = e.EmitItem(definitionResultID, []uint64{definitionRangeID}, documentID)
+	hoverResultID := e.EmitHoverResult(protocol.NewMarkupContent(o.hover, protocol.Markdown))
+	_ = e.EmitTextDocumentHover(resultSetID, hoverResultID)
+
+	if o.withReference {
+		referenceRangeID := e.EmitRange(protocol.Pos{Line: 6, Character: 1}, protocol.Pos{Line: 6, Character: 4})
+		_ = e.EmitNext(referenceRangeID, resultSetID)
+		rangeIDs = append(rangeIDs, referenceRangeID)
+	}
+
+	if o.withMoniker {
+		monikerID := e.EmitMoniker("export", "gomod", "github.com/test/repo:Foo")
+		packageInformationID := e.EmitPackageInformation("github.com/test/repo", "gomod", "v1.0.0")
+		_ = e.EmitPackageInformationEdge(monikerID, packageInformationID)
+		_ = e.EmitMonikerEdge(resultSetID, monikerID)
+	}
+
+	_ = e.EmitContains(documentID, rangeIDs)
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing emitter: %s", err)
+	}
+
+	elements, err := dump.Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	return dump.NewGraph(elements)
+}
+
+func TestDiffIdentical(t *testing.T) {
+	o := options{root: "/dev/a", hover: "func Foo()", definitionAt: protocol.Pos{Line: 2, Character: 5}, withMoniker: true, withReference: true}
+	before := emitGraph(t, o)
+	o.root = "/dev/b"
+	after := emitGraph(t, o)
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("unexpected error diffing dumps: %s", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes between checkouts, got %+v", changes)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := emitGraph(t, options{root: "/dev/a", hover: "func Foo()", definitionAt: protocol.Pos{Line: 2, Character: 5}, withMoniker: true})
+	after := emitGraph(t, options{root: "/dev/b", hover: "func Foo() error", definitionAt: protocol.Pos{Line: 2, Character: 5}, withReference: true})
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("unexpected error diffing dumps: %s", err)
+	}
+
+	var buf bytes.Buffer
+	Write(changes, &buf)
+
+	expected := strings.Join([]string{
+		"changed hover main.go:3:6-3:9",
+		"  - func Foo()",
+		"  + func Foo() error",
+		"removed monikers main.go:3:6-3:9",
+		"  - export gomod github.com/test/repo:Foo (github.com/test/repo@v1.0.0)",
+		"added definitions main.go:7:2-7:5",
+		"  + main.go:3:6-3:9",
+		"added hover main.go:7:2-7:5",
+		"  + func Foo() error",
+	}, "\n") + "\n"
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected diff output (-want +got): %s", diff)
+	}
+}
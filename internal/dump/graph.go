@@ -0,0 +1,431 @@
+package dump
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// Location is a range within a document of a dump.
+type Location struct {
+	URI   string
+	Range protocol.RangeData
+}
+
+// Moniker is a moniker attached to a range along with its package information, if any.
+type Moniker struct {
+	Kind           string
+	Scheme         string
+	Identifier     string
+	PackageName    string
+	PackageVersion string
+}
+
+// Graph is an in-memory index of the elements of a dump that answers the same queries
+// as a code intelligence backend: definitions, references, implementations, hover text,
+// and monikers of the range at a given position.
+type Graph struct {
+	projectRoot        string
+	documents          map[uint64]protocol.Document
+	documentsByURI     map[string]uint64
+	ranges             map[uint64]protocol.Range
+	documentRanges     map[uint64][]uint64          // documentID -> rangeIDs
+	next               map[uint64]uint64            // rangeID or resultSetID -> resultSetID
+	results            map[string]map[uint64]uint64 // edge label -> outV -> result ID
+	items              map[uint64][]protocol.Item   // result ID -> item edges
+	hoverResults       map[uint64]protocol.HoverResult
+	monikers           map[uint64]protocol.Moniker
+	monikerEdges       map[uint64][]uint64 // rangeID or resultSetID -> monikerIDs
+	packageInformation map[uint64]protocol.PackageInformation
+	monikerPackages    map[uint64]uint64 // monikerID -> packageInformationID
+}
+
+// NewGraph indexes the given elements, which are the values returned by Read.
+func NewGraph(elements []interface{}) *Graph {
+	g := &Graph{
+		documents:          map[uint64]protocol.Document{},
+		documentsByURI:     map[string]uint64{},
+		ranges:             map[uint64]protocol.Range{},
+		documentRanges:     map[uint64][]uint64{},
+		next:               map[uint64]uint64{},
+		results:            map[string]map[uint64]uint64{},
+		items:              map[uint64][]protocol.Item{},
+		hoverResults:       map[uint64]protocol.HoverResult{},
+		monikers:           map[uint64]protocol.Moniker{},
+		monikerEdges:       map[uint64][]uint64{},
+		packageInformation: map[uint64]protocol.PackageInformation{},
+		monikerPackages:    map[uint64]uint64{},
+	}
+
+	for _, element := range elements {
+		switch e := element.(type) {
+		case protocol.MetaData:
+			g.projectRoot = e.ProjectRoot
+		case protocol.Document:
+			g.documents[e.ID] = e
+			g.documentsByURI[e.URI] = e.ID
+		case protocol.Range:
+			g.ranges[e.ID] = e
+		case protocol.HoverResult:
+			g.hoverResults[e.ID] = e
+		case protocol.Moniker:
+			g.monikers[e.ID] = e
+		case protocol.PackageInformation:
+			g.packageInformation[e.ID] = e
+
+		case protocol.Contains:
+			g.documentRanges[e.OutV] = append(g.documentRanges[e.OutV], e.InVs...)
+		case protocol.Next:
+			g.next[e.OutV] = e.InV
+		case protocol.Item:
+			g.items[e.OutV] = append(g.items[e.OutV], e)
+		case protocol.TextDocumentDefinition:
+			g.addResult("textDocument/definition", e.OutV, e.InV)
+		case protocol.TextDocumentReferences:
+			g.addResult("textDocument/references", e.OutV, e.InV)
+		case protocol.TextDocumentImplementation:
+			g.addResult("textDocument/implementation", e.OutV, e.InV)
+		case protocol.TextDocumentHover:
+			g.addResult("textDocument/hover", e.OutV, e.InV)
+		case protocol.MonikerEdge:
+			g.monikerEdges[e.OutV] = append(g.monikerEdges[e.OutV], e.InV)
+		case protocol.PackageInformationEdge:
+			g.monikerPackages[e.OutV] = e.InV
+		}
+	}
+
+	return g
+}
+
+func (g *Graph) addResult(label string, outV, inV uint64) {
+	if _, ok := g.results[label]; !ok {
+		g.results[label] = map[uint64]uint64{}
+	}
+
+	g.results[label][outV] = inV
+}
+
+// ProjectRoot returns the project root recorded in the metadata of the dump.
+func (g *Graph) ProjectRoot() string {
+	return g.projectRoot
+}
+
+// RelativePath returns the path of the given document URI relative to the project root,
+// or the URI itself if the document lies outside of the project root.
+func (g *Graph) RelativePath(uri string) string {
+	if root := strings.TrimSuffix(g.projectRoot, "/") + "/"; strings.HasPrefix(uri, root) {
+		return strings.TrimPrefix(uri, root)
+	}
+
+	return uri
+}
+
+// Documents returns the URIs of the documents of the dump in lexicographic order.
+func (g *Graph) Documents() []string {
+	uris := make([]string, 0, len(g.documentsByURI))
+	for uri := range g.documentsByURI {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	return uris
+}
+
+// RangeInfo is what a dump knows about a single range of a document.
+type RangeInfo struct {
+	Range           protocol.RangeData
+	Definitions     []Location
+	References      []Location
+	Implementations []Location
+	Hover           interface{} // nil if the range has no hover text
+	Monikers        []Moniker
+}
+
+// Ranges returns what the dump knows about each range of the given document, ordered
+// by position.
+func (g *Graph) Ranges(uri string) []RangeInfo {
+	var infos []RangeInfo
+	for _, rangeID := range g.documentRanges[g.documentsByURI[uri]] {
+		r, ok := g.ranges[rangeID]
+		if !ok {
+			continue
+		}
+
+		info := RangeInfo{
+			Range:           r.RangeData,
+			Definitions:     g.locations(g.result("textDocument/definition", rangeID)),
+			References:      g.locations(g.result("textDocument/references", rangeID)),
+			Implementations: g.locations(g.result("textDocument/implementation", rangeID)),
+			Monikers:        g.monikersOf(rangeID),
+		}
+		if resultID, ok := g.result("textDocument/hover", rangeID); ok {
+			if hoverResult, ok := g.hoverResults[resultID]; ok {
+				info.Hover = hoverResult.Result.Contents
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if c := comparePos(infos[i].Range.Start, infos[j].Range.Start); c != 0 {
+			return c < 0
+		}
+
+		return comparePos(infos[i].Range.End, infos[j].Range.End) < 0
+	})
+
+	return infos
+}
+
+// HasDocument returns true if the dump contains a document with the given URI.
+func (g *Graph) HasDocument(uri string) bool {
+	_, ok := g.documentsByURI[uri]
+	return ok
+}
+
+// Definitions returns the definitions of the innermost range at the given position.
+func (g *Graph) Definitions(uri string, pos protocol.Pos) []Location {
+	return g.locations(g.resultsAt("textDocument/definition", uri, pos))
+}
+
+// References returns the references of the innermost range at the given position. The
+// references include the definitions of the range.
+func (g *Graph) References(uri string, pos protocol.Pos) []Location {
+	return g.locations(g.resultsAt("textDocument/references", uri, pos))
+}
+
+// Implementations returns the implementations of the innermost range at the given position.
+func (g *Graph) Implementations(uri string, pos protocol.Pos) []Location {
+	return g.locations(g.resultsAt("textDocument/implementation", uri, pos))
+}
+
+// Hover returns the hover contents and the range of the innermost range at the given
+// position that has hover text. The contents are returned as decoded from the dump.
+func (g *Graph) Hover(uri string, pos protocol.Pos) (interface{}, protocol.RangeData, bool) {
+	for _, rangeID := range g.rangesAt(uri, pos) {
+		if resultID, ok := g.result("textDocument/hover", rangeID); ok {
+			if hoverResult, ok := g.hoverResults[resultID]; ok {
+				return hoverResult.Result.Contents, g.ranges[rangeID].RangeData, true
+			}
+		}
+	}
+
+	return nil, protocol.RangeData{}, false
+}
+
+// Monikers returns the monikers of the innermost range at the given position that has
+// monikers, including the monikers attached to the result sets of the range.
+func (g *Graph) Monikers(uri string, pos protocol.Pos) []Moniker {
+	for _, rangeID := range g.rangesAt(uri, pos) {
+		if monikers := g.monikersOf(rangeID); len(monikers) > 0 {
+			return monikers
+		}
+	}
+
+	return nil
+}
+
+// rangesAt returns the identifiers of the ranges of the given document that enclose the
+// given position, innermost first.
+func (g *Graph) rangesAt(uri string, pos protocol.Pos) []uint64 {
+	documentID, ok := g.documentsByURI[uri]
+	if !ok {
+		return nil
+	}
+
+	var rangeIDs []uint64
+	for _, rangeID := range g.documentRanges[documentID] {
+		if r, ok := g.ranges[rangeID]; ok && contains(r.RangeData, pos) {
+			rangeIDs = append(rangeIDs, rangeID)
+		}
+	}
+
+	sort.Slice(rangeIDs, func(i, j int) bool {
+		// Ranges enclosing the same position are nested, so the innermost range is the one
+		// that starts last (or, for equal starts, the one that ends first).
+		ri, rj := g.ranges[rangeIDs[i]], g.ranges[rangeIDs[j]]
+		if ri.Start != rj.Start {
+			return comparePos(ri.Start, rj.Start) > 0
+		}
+
+		return comparePos(ri.End, rj.End) < 0
+	})
+
+	return rangeIDs
+}
+
+// resultsAt returns the result vertex reachable from the innermost range at the given
+// position via an edge with the given label.
+func (g *Graph) resultsAt(label, uri string, pos protocol.Pos) (uint64, bool) {
+	for _, rangeID := range g.rangesAt(uri, pos) {
+		if resultID, ok := g.result(label, rangeID); ok {
+			return resultID, true
+		}
+	}
+
+	return 0, false
+}
+
+// result follows the chain of next edges from the given range or result set until it
+// reaches a vertex with an outgoing edge with the given label.
+func (g *Graph) result(label string, id uint64) (uint64, bool) {
+	for _, id := range g.chain(id) {
+		if resultID, ok := g.results[label][id]; ok {
+			return resultID, true
+		}
+	}
+
+	return 0, false
+}
+
+// chain returns the given range or result set followed by the result sets reachable from
+// it via next edges.
+func (g *Graph) chain(id uint64) []uint64 {
+	ids := []uint64{id}
+	visited := map[uint64]struct{}{id: {}}
+
+	for {
+		nextID, ok := g.next[id]
+		if !ok {
+			return ids
+		}
+		if _, ok := visited[nextID]; ok {
+			return ids
+		}
+
+		visited[nextID] = struct{}{}
+		ids = append(ids, nextID)
+		id = nextID
+	}
+}
+
+// locations returns the sorted and de-duplicated locations of the ranges attached to the
+// given result vertex via item edges.
+func (g *Graph) locations(resultID uint64, ok bool) []Location {
+	if !ok {
+		return nil
+	}
+
+	seen := map[Location]struct{}{}
+	var locations []Location
+	for _, item := range g.items[resultID] {
+		document, ok := g.documents[item.Document]
+		if !ok {
+			continue
+		}
+
+		for _, rangeID := range item.InVs {
+			r, ok := g.ranges[rangeID]
+			if !ok {
+				continue
+			}
+
+			location := Location{URI: document.URI, Range: r.RangeData}
+			if _, ok := seen[location]; !ok {
+				seen[location] = struct{}{}
+				locations = append(locations, location)
+			}
+		}
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].URI != locations[j].URI {
+			return locations[i].URI < locations[j].URI
+		}
+
+		return comparePos(locations[i].Range.Start, locations[j].Range.Start) < 0
+	})
+
+	return locations
+}
+
+// monikersOf returns the monikers attached to the given range and its result sets.
+func (g *Graph) monikersOf(id uint64) []Moniker {
+	var monikers []Moniker
+	for _, id := range g.chain(id) {
+		for _, monikerID := range g.monikerEdges[id] {
+			moniker, ok := g.monikers[monikerID]
+			if !ok {
+				continue
+			}
+
+			m := Moniker{Kind: moniker.Kind, Scheme: moniker.Scheme, Identifier: moniker.Identifier}
+			if packageInformation, ok := g.packageInformation[g.monikerPackages[monikerID]]; ok {
+				m.PackageName = packageInformation.Name
+				m.PackageVersion = packageInformation.Version
+			}
+			monikers = append(monikers, m)
+		}
+	}
+
+	sort.Slice(monikers, func(i, j int) bool {
+		if monikers[i].Kind != monikers[j].Kind {
+			return monikers[i].Kind < monikers[j].Kind
+		}
+
+		return monikers[i].Identifier < monikers[j].Identifier
+	})
+
+	return monikers
+}
+
+// contains returns true if the given position lies within the given range. The end of
+// the range is inclusive so that a cursor placed directly after an identifier still
+// refers to it.
+func contains(r protocol.RangeData, pos protocol.Pos) bool {
+	return comparePos(r.Start, pos) <= 0 && comparePos(pos, r.End) <= 0
+}
+
+func comparePos(a, b protocol.Pos) int {
+	if a.Line != b.Line {
+		return a.Line - b.Line
+	}
+
+	return a.Character - b.Character
+}
+
+// HoverText returns the text of the given hover contents, which may be a string, a
+// marked string, markup content, or a list of those.
+func HoverText(contents interface{}) (string, error) {
+	// Normalize contents that were not read from a serialized dump
+	serialized, err := json.Marshal(contents)
+	if err != nil {
+		return "", err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(serialized, &decoded); err != nil {
+		return "", err
+	}
+
+	switch v := decoded.(type) {
+	case string:
+		return v, nil
+
+	case map[string]interface{}:
+		value, _ := v["value"].(string)
+		if language, ok := v["language"].(string); ok {
+			return fmt.Sprintf("```%s\n%s\n```", language, value), nil
+		}
+
+		return value, nil
+
+	case []interface{}:
+		var parts []string
+		for _, part := range v {
+			text, err := HoverText(part)
+			if err != nil {
+				return "", err
+			}
+
+			parts = append(parts, text)
+		}
+
+		return strings.Join(parts, "\n\n"), nil
+	}
+
+	return "", fmt.Errorf("unexpected hover contents %s", serialized)
+}
@@ -0,0 +1,132 @@
+package dump
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+// readGraph emits a dump with the given function, serializes it, and reads it back.
+func readGraph(t *testing.T, emit func(e *writer.Emitter)) *Graph {
+	var buf bytes.Buffer
+	e := writer.NewEmitter(writer.NewJSONWriter(&buf))
+	emit(e)
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing emitter: %s", err)
+	}
+
+	elements, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	return NewGraph(elements)
+}
+
+func TestGraph(t *testing.T) {
+	g := readGraph(t, func(e *writer.Emitter) {
+		e.EmitMetaData("file:///dev/repo", protocol.ToolInfo{Name: "lsif-go", Version: "dev"})
+		projectID := e.EmitProject("go")
+		mainID := e.EmitDocument("go", "/dev/repo/main.go")
+		implID := e.EmitDocument("go", "/dev/repo/impl.go")
+
+		// type Foo interface (main.go:2:5), referenced in a call at main.go:6:1 which
+		// is itself enclosed by a wider range; implemented by Bar (impl.go:3:5)
+		definitionRangeID := e.EmitRange(protocol.Pos{Line: 2, Character: 5}, protocol.Pos{Line: 2, Character: 8})
+		referenceRangeID := e.EmitRange(protocol.Pos{Line: 6, Character: 1}, protocol.Pos{Line: 6, Character: 4})
+		enclosingRangeID := e.EmitRange(protocol.Pos{Line: 6, Character: 0}, protocol.Pos{Line: 6, Character: 10})
+		implementationRangeID := e.EmitRange(protocol.Pos{Line: 3, Character: 5}, protocol.Pos{Line: 3, Character: 8})
+
+		resultSetID := e.EmitResultSet()
+		_ = e.EmitNext(definitionRangeID, resultSetID)
+		_ = e.EmitNext(referenceRangeID, resultSetID)
+
+		definitionResultID := e.EmitDefinitionResult()
+		_ = e.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+		_ = e.EmitItem(definitionResultID, []uint64{definitionRangeID}, mainID)
+
+		referenceResultID := e.EmitReferenceResult()
+		_ = e.EmitTextDocumentReferences(resultSetID, referenceResultID)
+		_ = e.EmitItemOfDefinitions(referenceResultID, []uint64{definitionRangeID}, mainID)
+		_ = e.EmitItemOfReferences(referenceResultID, []uint64{referenceRangeID, definitionRangeID}, mainID)
+
+		implementationResultID := e.EmitImplementationResult()
+		_ = e.EmitTextDocumentImplementation(resultSetID, implementationResultID)
+		_ = e.EmitItem(implementationResultID, []uint64{implementationRangeID}, implID)
+
+		hoverResultID := e.EmitHoverResult(protocol.NewMarkupContent("```go\ntype Foo interface\n```", protocol.Markdown))
+		_ = e.EmitTextDocumentHover(resultSetID, hoverResultID)
+
+		monikerID := e.EmitMoniker("export", "gomod", "github.com/test/repo:Foo")
+		packageInformationID := e.EmitPackageInformation("github.com/test/repo", "gomod", "v1.0.0")
+		_ = e.EmitPackageInformationEdge(monikerID, packageInformationID)
+		_ = e.EmitMonikerEdge(resultSetID, monikerID)
+
+		_ = e.EmitContains(mainID, []uint64{definitionRangeID, referenceRangeID, enclosingRangeID})
+		_ = e.EmitContains(implID, []uint64{implementationRangeID})
+		_ = e.EmitContains(projectID, []uint64{mainID, implID})
+	})
+
+	mainURI := "file:///dev/repo/main.go"
+	definition := Location{URI: mainURI, Range: protocol.RangeData{Start: protocol.Pos{Line: 2, Character: 5}, End: protocol.Pos{Line: 2, Character: 8}}}
+	reference := Location{URI: mainURI, Range: protocol.RangeData{Start: protocol.Pos{Line: 6, Character: 1}, End: protocol.Pos{Line: 6, Character: 4}}}
+	implementation := Location{URI: "file:///dev/repo/impl.go", Range: protocol.RangeData{Start: protocol.Pos{Line: 3, Character: 5}, End: protocol.Pos{Line: 3, Character: 8}}}
+
+	if root := g.ProjectRoot(); root != "file:///dev/repo" {
+		t.Errorf("unexpected project root. want=%q have=%q", "file:///dev/repo", root)
+	}
+
+	t.Run("definitions", func(t *testing.T) {
+		// The innermost range at the reference position is the reference, not the enclosing range
+		if diff := cmp.Diff([]Location{definition}, g.Definitions(mainURI, protocol.Pos{Line: 6, Character: 2})); diff != "" {
+			t.Errorf("unexpected definitions (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		if diff := cmp.Diff([]Location{definition, reference}, g.References(mainURI, protocol.Pos{Line: 2, Character: 8})); diff != "" {
+			t.Errorf("unexpected references (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("implementations", func(t *testing.T) {
+		if diff := cmp.Diff([]Location{implementation}, g.Implementations(mainURI, protocol.Pos{Line: 2, Character: 5})); diff != "" {
+			t.Errorf("unexpected implementations (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		contents, r, ok := g.Hover(mainURI, protocol.Pos{Line: 6, Character: 1})
+		if !ok {
+			t.Fatalf("expected hover text")
+		}
+
+		expectedContents := map[string]interface{}{"kind": "markdown", "value": "```go\ntype Foo interface\n```"}
+		if diff := cmp.Diff(expectedContents, contents); diff != "" {
+			t.Errorf("unexpected hover contents (-want +got): %s", diff)
+		}
+		if diff := cmp.Diff(reference.Range, r); diff != "" {
+			t.Errorf("unexpected hover range (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("monikers", func(t *testing.T) {
+		expected := []Moniker{{Kind: "export", Scheme: "gomod", Identifier: "github.com/test/repo:Foo", PackageName: "github.com/test/repo", PackageVersion: "v1.0.0"}}
+		if diff := cmp.Diff(expected, g.Monikers(mainURI, protocol.Pos{Line: 2, Character: 6})); diff != "" {
+			t.Errorf("unexpected monikers (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("no range", func(t *testing.T) {
+		if locations := g.Definitions(mainURI, protocol.Pos{Line: 4, Character: 0}); len(locations) != 0 {
+			t.Errorf("unexpected definitions: %v", locations)
+		}
+		if locations := g.Definitions("file:///dev/repo/missing.go", protocol.Pos{Line: 2, Character: 5}); len(locations) != 0 {
+			t.Errorf("unexpected definitions: %v", locations)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package dump
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sourcegraph/lsif-go/internal/compression"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// Unknown is an element whose label has no corresponding protocol type. It is returned
+// as-is so that readers of a dump can report or skip it.
+type Unknown struct {
+	ID    uint64          `json:"id"`
+	Type  string          `json:"type"`
+	Label string          `json:"label"`
+	Raw   json.RawMessage `json:"-"`
+}
+
+type decoder func(line []byte) (interface{}, error)
+
+func decodeAs[T any](line []byte) (interface{}, error) {
+	var v T
+	if err := json.Unmarshal(line, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// vertexDecoders maps vertex labels to a decoder of the matching protocol (or lsif) type.
+var vertexDecoders = map[string]decoder{
+	"metaData":             decodeAs[protocol.MetaData],
+	"project":              decodeAs[protocol.Project],
+	"document":             decodeAs[protocol.Document],
+	"range":                decodeAs[protocol.Range],
+	"resultSet":            decodeAs[protocol.ResultSet],
+	"hoverResult":          decodeAs[protocol.HoverResult],
+	"definitionResult":     decodeAs[protocol.DefinitionResult],
+	"referenceResult":      decodeAs[protocol.ReferenceResult],
+	"implementationResult": decodeAs[protocol.ImplementationResult],
+	"typeDefinitionResult": decodeAs[lsif.TypeDefinitionResult],
+	"moniker":              decodeAs[protocol.Moniker],
+	"packageInformation":   decodeAs[protocol.PackageInformation],
+	"diagnosticResult":     decodeAs[lsif.DiagnosticResult],
+	"documentSymbolResult": decodeAs[lsif.DocumentSymbolResult],
+	"foldingRangeResult":   decodeAs[lsif.FoldingRangeResult],
+}
+
+// edgeDecoders maps edge labels to a decoder of the matching protocol (or lsif) type.
+var edgeDecoders = map[string]decoder{
+	"contains":                    decodeAs[protocol.Contains],
+	"item":                        decodeAs[protocol.Item],
+	"next":                        decodeAs[protocol.Next],
+	"textDocument/hover":          decodeAs[protocol.TextDocumentHover],
+	"textDocument/definition":     decodeAs[protocol.TextDocumentDefinition],
+	"textDocument/references":     decodeAs[protocol.TextDocumentReferences],
+	"textDocument/implementation": decodeAs[protocol.TextDocumentImplementation],
+	"textDocument/typeDefinition": decodeAs[lsif.TextDocumentTypeDefinition],
+	"moniker":                     decodeAs[protocol.MonikerEdge],
+	"nextMoniker":                 decodeAs[protocol.NextMonikerEdge],
+	"packageInformation":          decodeAs[protocol.PackageInformationEdge],
+	"textDocument/diagnostic":     decodeAs[lsif.TextDocumentDiagnostic],
+	"textDocument/documentSymbol": decodeAs[lsif.TextDocumentDocumentSymbol],
+	"textDocument/foldingRange":   decodeAs[lsif.TextDocumentFoldingRange],
+	"callHierarchy/call":          decodeAs[lsif.CallHierarchyCall],
+	"typeHierarchy/supertype":     decodeAs[lsif.TypeHierarchySupertype],
+}
+
+// ReadFile reads the dump at the given path. Gzip and zstd compressed dumps are
+// decompressed transparently.
+func ReadFile(filename string) ([]interface{}, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := compression.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return Read(r)
+}
+
+// Read decodes the line-delimited JSON elements of an LSIF dump. Each element is returned
+// as the protocol (or lsif) type written by the indexer's emitter (e.g. protocol.Range), so
+// that the result can be treated the same way as the elements captured from an in-process indexer.
+func Read(r io.Reader) ([]interface{}, error) {
+	var elements []interface{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		element, err := decodeElement(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+
+		elements = append(elements, element)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
+
+// decodeElement decodes a single line of a dump into the protocol type matching its label.
+func decodeElement(line []byte) (interface{}, error) {
+	var header Unknown
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, err
+	}
+
+	decoders := vertexDecoders
+	if header.Type == "edge" {
+		decoders = edgeDecoders
+	}
+
+	decode, ok := decoders[header.Label]
+	if !ok {
+		header.Raw = append(json.RawMessage(nil), line...)
+		return header, nil
+	}
+
+	return decode(line)
+}
@@ -0,0 +1,164 @@
+package dump
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+type capturingWriter struct {
+	elements []interface{}
+}
+
+func (w *capturingWriter) Write(v interface{}) { w.elements = append(w.elements, v) }
+func (w *capturingWriter) Flush() error        { return nil }
+
+// teeWriter writes each element to both of the given writers.
+type teeWriter struct {
+	a, b writer.JSONWriter
+}
+
+func (w teeWriter) Write(v interface{}) { w.a.Write(v); w.b.Write(v) }
+func (w teeWriter) Flush() error {
+	if err := w.a.Flush(); err != nil {
+		return err
+	}
+
+	return w.b.Flush()
+}
+
+func TestRead(t *testing.T) {
+	var buf bytes.Buffer
+	captured := &capturingWriter{}
+	e := writer.NewEmitter(teeWriter{captured, writer.NewJSONWriter(&buf)})
+
+	e.EmitMetaData("file:///dev/repo", protocol.ToolInfo{Name: "lsif-go", Version: "dev"})
+	projectID := e.EmitProject("go")
+	documentID := e.EmitDocument("go", "/dev/repo/main.go")
+	rangeID := e.EmitRange(protocol.Pos{Line: 2, Character: 5}, protocol.Pos{Line: 2, Character: 8})
+	resultSetID := e.EmitResultSet()
+	_ = e.EmitNext(rangeID, resultSetID)
+	definitionResultID := e.EmitDefinitionResult()
+	_ = e.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+	_ = e.EmitItemOfDefinitions(definitionResultID, []uint64{rangeID}, documentID)
+	monikerID := e.EmitMoniker("export", "gomod", "github.com/test/repo:Foo")
+	packageInformationID := e.EmitPackageInformation("github.com/test/repo", "gomod", "v1.0.0")
+	_ = e.EmitPackageInformationEdge(monikerID, packageInformationID)
+	_ = e.EmitMonikerEdge(resultSetID, monikerID)
+	_ = e.EmitContains(documentID, []uint64{rangeID})
+	_ = e.EmitContains(projectID, []uint64{documentID})
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing emitter: %s", err)
+	}
+
+	elements, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	if diff := cmp.Diff(captured.elements, elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+}
+
+func TestReadDiagnostics(t *testing.T) {
+	var buf bytes.Buffer
+	w := writer.NewJSONWriter(&buf)
+
+	diagnostics := []lsif.Diagnostic{
+		{
+			Range:    lsif.Range{Start: protocol.Pos{Line: 3, Character: 1}, End: protocol.Pos{Line: 3, Character: 4}},
+			Severity: lsif.SeverityError,
+			Source:   "go/types",
+			Message:  "undefined: foo",
+		},
+	}
+	expected := []interface{}{
+		lsif.NewDiagnosticResult(2, diagnostics),
+		lsif.NewTextDocumentDiagnostic(3, 1, 2),
+	}
+	for _, element := range expected {
+		w.Write(element)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing writer: %s", err)
+	}
+
+	elements, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	if diff := cmp.Diff(expected, elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+}
+
+func TestReadDocumentSymbols(t *testing.T) {
+	var buf bytes.Buffer
+	w := writer.NewJSONWriter(&buf)
+
+	symbols := []lsif.DocumentSymbol{
+		{
+			Name:           "Point",
+			Kind:           lsif.SymbolKindStruct,
+			Range:          lsif.Range{Start: protocol.Pos{Line: 2, Character: 0}, End: protocol.Pos{Line: 4, Character: 1}},
+			SelectionRange: lsif.Range{Start: protocol.Pos{Line: 2, Character: 5}, End: protocol.Pos{Line: 2, Character: 10}},
+			Children: []lsif.DocumentSymbol{
+				{
+					Name:           "X",
+					Kind:           lsif.SymbolKindField,
+					Range:          lsif.Range{Start: protocol.Pos{Line: 3, Character: 1}, End: protocol.Pos{Line: 3, Character: 6}},
+					SelectionRange: lsif.Range{Start: protocol.Pos{Line: 3, Character: 1}, End: protocol.Pos{Line: 3, Character: 2}},
+				},
+			},
+		},
+	}
+	expected := []interface{}{
+		lsif.NewDocumentSymbolResult(2, symbols),
+		lsif.NewTextDocumentDocumentSymbol(3, 1, 2),
+	}
+	for _, element := range expected {
+		w.Write(element)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing writer: %s", err)
+	}
+
+	elements, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	if diff := cmp.Diff(expected, elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+}
+
+func TestReadUnknownLabel(t *testing.T) {
+	line := `{"id":1,"type":"vertex","label":"folding"}`
+
+	elements, err := Read(strings.NewReader(line + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	expected := []interface{}{
+		Unknown{ID: 1, Type: "vertex", Label: "folding", Raw: []byte(line)},
+	}
+	if diff := cmp.Diff(expected, elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+}
+
+func TestReadMalformed(t *testing.T) {
+	if _, err := Read(strings.NewReader("{\"id\":1}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected error on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/command"
+)
+
+// ChangedFiles returns the absolute paths of the files in the work tree of the git project
+// containing the given directory that differ from the given revision. This includes files
+// that were added, modified, or deleted since the revision, as well as untracked files.
+func ChangedFiles(dir, revision string) ([]string, error) {
+	root, err := TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Disable rename detection so that both the old and new path of a moved file are listed
+	diff, err := command.Run(root, "git", "diff", "--name-only", "--no-renames", revision, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %v\n%s", revision, err, diff)
+	}
+
+	untracked, err := command.Run(root, "git", "ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %v\n%s", err, untracked)
+	}
+
+	var files []string
+	for _, output := range []string{diff, untracked} {
+		for _, line := range strings.Split(output, "\n") {
+			if line != "" {
+				files = append(files, filepath.Join(root, line))
+			}
+		}
+	}
+
+	return files, nil
+}
@@ -2,6 +2,8 @@ package git
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/sourcegraph/lsif-go/internal/command"
 )
@@ -18,6 +20,33 @@ func InferModuleVersion(dir string) (string, error) {
 		return version, nil
 	}
 
+	return inferCommitVersion(dir)
+}
+
+// InferNestedModuleVersion returns the version of the module declared in the given directory
+// of the git repository with the given root. As with the go command, a module in a subdirectory
+// is versioned by tags prefixed with its path (e.g. lib/v1.2.0 for the module in lib). If no
+// such tag points at the work tree commit, the version is the short revhash of that commit, as
+// tags of the root module or of other nested modules do not version this module.
+func InferNestedModuleVersion(repositoryRoot, dir string) (string, error) {
+	relative, err := filepath.Rel(repositoryRoot, dir)
+	if err != nil || relative == "." {
+		return InferModuleVersion(dir)
+	}
+
+	tags, err := command.Run(dir, "git", "tag", "-l", "--points-at", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to tags for current commit: %v\n%s", err, tags)
+	}
+	if version, ok := nestedModuleTag(strings.Split(tags, "\n"), filepath.ToSlash(relative)); ok {
+		return version, nil
+	}
+
+	return inferCommitVersion(dir)
+}
+
+// inferCommitVersion returns the short revhash of the work tree commit.
+func inferCommitVersion(dir string) (string, error) {
 	commit, err := command.Run(dir, "git", "rev-parse", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current commit: %v\n%s", err, commit)
@@ -25,3 +54,14 @@ func InferModuleVersion(dir string) (string, error) {
 
 	return commit[:12], nil
 }
+
+// nestedModuleTag returns the version of the first tag of the form prefix/version.
+func nestedModuleTag(tags []string, prefix string) (string, bool) {
+	for _, tag := range tags {
+		if version := strings.TrimPrefix(tag, prefix+"/"); version != tag && version != "" {
+			return version, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestNestedModuleTag(t *testing.T) {
+	testCases := []struct {
+		tags     []string
+		prefix   string
+		expected string
+		ok       bool
+	}{
+		{tags: []string{"v1.0.0", "lib/v1.2.0"}, prefix: "lib", expected: "v1.2.0", ok: true},
+		{tags: []string{"lib/nested/v0.1.0", "lib/v1.2.0"}, prefix: "lib/nested", expected: "v0.1.0", ok: true},
+		{tags: []string{"v1.0.0", "library/v1.2.0"}, prefix: "lib", ok: false},
+		{tags: []string{""}, prefix: "lib", ok: false},
+	}
+
+	for _, testCase := range testCases {
+		version, ok := nestedModuleTag(testCase.tags, testCase.prefix)
+		if ok != testCase.ok || version != testCase.expected {
+			t.Errorf("unexpected version for %v (%s). want=%q (%v) have=%q (%v)", testCase.tags, testCase.prefix, testCase.expected, testCase.ok, version, ok)
+		}
+	}
+}
+
+func TestInferNestedModuleVersion(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "lib")
+	if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		t.Fatalf("unexpected error creating directory: %s", err)
+	}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(),
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("unexpected error running git %v: %s\n%s", args, err, out)
+		}
+
+		return string(out)
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	commit := git("rev-parse", "HEAD")[:12]
+
+	testCases := []struct {
+		tag      string
+		dir      string
+		expected string
+	}{
+		{tag: "", dir: dir, expected: commit},
+		{tag: "v1.0.0", dir: root, expected: "v1.0.0"},
+		{tag: "", dir: dir, expected: commit},
+		{tag: "other/v2.0.0", dir: dir, expected: commit},
+		{tag: "lib/v1.2.0", dir: dir, expected: "v1.2.0"},
+	}
+
+	for _, testCase := range testCases {
+		if testCase.tag != "" {
+			git("tag", testCase.tag)
+		}
+
+		version, err := InferNestedModuleVersion(root, testCase.dir)
+		if err != nil {
+			t.Fatalf("unexpected error inferring version: %s", err)
+		}
+		if version != testCase.expected {
+			t.Errorf("unexpected version of %s after tagging %q. want=%q have=%q", testCase.dir, testCase.tag, testCase.expected, version)
+		}
+	}
+}
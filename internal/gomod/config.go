@@ -0,0 +1,55 @@
+package gomod
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFilename is the name of the optional configuration file read from the root of the
+// repository being indexed.
+const ConfigFilename = ".lsif-go.yaml"
+
+// ReadConfigImportMap reads the import map of the given configuration file, which maps
+// import path prefixes to repository URLs in the same way as ReadImportMap:
+//
+//	import-map:
+//	  go.company.com/x: https://git.company.com/x
+//	  "go.company.com/y": git.company.com/y # https is assumed
+//
+// Top-level keys other than import-map are ignored.
+func ReadConfigImportMap(filename string) (map[string]string, error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := parseConfigImportMap(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("malformed config %s: %v", filename, err)
+	}
+
+	return normalizeImportMap(rules), nil
+}
+
+// parseConfigImportMap returns the entries of the import-map section of the given config.
+func parseConfigImportMap(contents string) (map[string]string, error) {
+	var config struct {
+		ImportMap map[string]string `yaml:"import-map"`
+	}
+	if err := yaml.Unmarshal([]byte(contents), &config); err != nil {
+		return nil, err
+	}
+
+	rules := map[string]string{}
+	for prefix, repo := range config.ImportMap {
+		if prefix == "" || repo == "" {
+			return nil, fmt.Errorf("import-map: expected `import/path/prefix: repository`, got %q: %q", prefix, repo)
+		}
+
+		rules[prefix] = repo
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,46 @@
+package gomod
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfigImportMap(t *testing.T) {
+	contents := `
+# Vanity import paths hosted on internal git servers
+import-map:
+  go.company.com/x: https://git.company.com/x
+  "go.company.com/y": git.company.com/y # https is assumed
+  'go.company.com/z': "ssh://git.company.com/z"
+
+other:
+  go.company.com/ignored: https://git.company.com/ignored
+`
+
+	rules, err := parseConfigImportMap(contents)
+	if err != nil {
+		t.Fatalf("unexpected error parsing config: %s", err)
+	}
+
+	expected := map[string]string{
+		"go.company.com/x": "https://git.company.com/x",
+		"go.company.com/y": "git.company.com/y",
+		"go.company.com/z": "ssh://git.company.com/z",
+	}
+	if diff := cmp.Diff(expected, rules); diff != "" {
+		t.Errorf("unexpected import map (-want +got): %s", diff)
+	}
+}
+
+func TestParseConfigImportMapMalformed(t *testing.T) {
+	for _, contents := range []string{
+		"import-map: go.company.com/x",
+		"import-map:\n  go.company.com/x\n",
+		"import-map:\n  go.company.com/x:\n",
+	} {
+		if _, err := parseConfigImportMap(contents); err == nil {
+			t.Errorf("expected error parsing %q", contents)
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"io"
 	"log"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
@@ -15,7 +17,6 @@ import (
 
 	"github.com/sourcegraph/lsif-go/internal/command"
 	"github.com/sourcegraph/lsif-go/internal/output"
-	"golang.org/x/tools/go/vcs"
 )
 
 type GoModule struct {
@@ -26,37 +27,21 @@ type GoModule struct {
 // ListDependencies returns a map from dependency import paths to the imported module's name
 // and version as declared by the go.mod file in the current directory. The given root module
 // and version are used to resolve replace directives with local file paths. The root module
-// is expected to be a resolved import path (a valid URL, including a scheme).
-func ListDependencies(dir, rootModule, rootVersion string, outputOptions output.Options) (dependencies map[string]GoModule, err error) {
-	if !isModule(dir) {
+// is expected to be a resolved import path (a valid URL, including a scheme). If the module
+// is vendored, the dependencies are read from vendor/modules.txt instead of the module graph.
+// The repositories of the dependencies are determined by the given resolver.
+func ListDependencies(dir, rootModule, rootVersion string, resolver *Resolver, outputOptions output.Options) (dependencies map[string]GoModule, err error) {
+	if !isModule(dir) && !IsWorkspace(dir) {
 		log.Println("WARNING: No go.mod file found in current directory.")
 		return nil, nil
 	}
 
 	resolve := func() {
-		var output, modOutput string
-
-		output, err = command.Run(dir, "go", "list", "-mod=readonly", "-m", "-json", "all")
-		if err != nil {
-			err = fmt.Errorf("failed to list modules: %v\n%s", err, output)
-			return
+		if isVendored(dir) {
+			dependencies, err = listVendoredDependencies(dir, rootVersion)
+		} else {
+			dependencies, err = listModuleDependencies(dir, rootVersion)
 		}
-
-		// The reason we run this command separate is because we want the
-		// information about this package specifically. Currently, it seems
-		// that "go list all" will place the current modules information first
-		// in the list, but we don't know that that is guaranteed.
-		//
-		// Because of that, we do a separate execution to guarantee we get only
-		// this package information to use to determine the corresponding
-		// goVersion.
-		modOutput, err = command.Run(dir, "go", "list", "-mod=readonly", "-m", "-json")
-		if err != nil {
-			err = fmt.Errorf("failed to list module info: %v\n%s", err, output)
-			return
-		}
-
-		dependencies, err = parseGoListOutput(output, modOutput, rootVersion)
 		if err != nil {
 			return
 		}
@@ -66,7 +51,7 @@ func ListDependencies(dir, rootModule, rootVersion string, outputOptions output.
 			modules = append(modules, module.Name)
 		}
 
-		resolvedImportPaths := resolveImportPaths(rootModule, modules)
+		resolvedImportPaths := resolveImportPaths(resolver, rootModule, modules)
 		mapImportPaths(dependencies, resolvedImportPaths)
 	}
 
@@ -74,13 +59,59 @@ func ListDependencies(dir, rootModule, rootVersion string, outputOptions output.
 	return dependencies, err
 }
 
+// listModuleDependencies returns the unresolved dependencies of the module in the given
+// directory as reported by `go list -m all`.
+func listModuleDependencies(dir, rootVersion string) (map[string]GoModule, error) {
+	output, err := command.Run(dir, "go", "list", "-mod=readonly", "-m", "-json", "all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules: %v\n%s", err, output)
+	}
+
+	// The reason we run this command separate is because we want the
+	// information about this package specifically. Currently, it seems
+	// that "go list all" will place the current modules information first
+	// in the list, but we don't know that that is guaranteed.
+	//
+	// Because of that, we do a separate execution to guarantee we get only
+	// this package information to use to determine the corresponding
+	// goVersion.
+	modOutput, err := command.Run(dir, "go", "list", "-mod=readonly", "-m", "-json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module info: %v\n%s", err, modOutput)
+	}
+
+	return parseGoListOutput(output, modOutput, rootVersion)
+}
+
+// listVendoredDependencies returns the unresolved dependencies of the module in the given
+// directory as recorded in vendor/modules.txt. The go command cannot compute the module
+// graph (`go list -m all`) from the vendor directory.
+func listVendoredDependencies(dir, rootVersion string) (map[string]GoModule, error) {
+	modulesTxt, err := os.ReadFile(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vendor/modules.txt: %v", err)
+	}
+
+	modOutput, err := command.Run(dir, "go", "list", "-mod=vendor", "-m", "-json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module info: %v\n%s", err, modOutput)
+	}
+
+	return parseVendoredModules(string(modulesTxt), modOutput, rootVersion)
+}
+
 // listProjectDependencies finds any packages from "$ go list all" that are NOT declared
-// as part of the current project.
+// as part of the current project. The project packages are selected by the given patterns,
+// or by ./... if no patterns are given.
 //
 // NOTE: This is different from the other dependencies stored in the indexer because it
 // does not modules, but packages.
-func ListProjectDependencies(projectRoot string) ([]string, error) {
-	projectPackageOutput, err := command.Run(projectRoot, "go", "list", "./...")
+func ListProjectDependencies(projectRoot string, patterns ...string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	projectPackageOutput, err := command.Run(projectRoot, "go", append([]string{"list"}, patterns...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list project packages: %v\n%s", err, projectPackageOutput)
 	}
@@ -153,13 +184,73 @@ func parseGoListOutput(output, modOutput, rootVersion string) (map[string]GoModu
 		}
 	}
 
-	var thisModule jsonModule
-	if err := json.NewDecoder(strings.NewReader(modOutput)).Decode(&thisModule); err != nil {
+	thisModule, err := parseThisModule(modOutput)
+	if err != nil {
 		return nil, err
 	}
 
-	if thisModule.GoVersion == "" {
-		return nil, errors.New("could not find GoVersion for current module")
+	setGolangDependency(dependencies, thisModule.GoVersion)
+
+	return dependencies, nil
+}
+
+// parseVendoredModules parses the module lines of vendor/modules.txt, which have the form
+// `# path version` or `# path [version] => replacement [version]`. As with parseGoListOutput,
+// this method returns a map from import paths to module names and versions that respect the
+// replacement directives, and local replacements use the given root version. The module
+// being indexed is read from the given output of `go list -m`.
+func parseVendoredModules(modulesTxt, modOutput, rootVersion string) (map[string]GoModule, error) {
+	thisModule, err := parseThisModule(modOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies := map[string]GoModule{
+		thisModule.Name: {Name: thisModule.Name, Version: rootVersion},
+	}
+
+	for _, line := range strings.Split(modulesTxt, "\n") {
+		if !strings.HasPrefix(line, "# ") {
+			// Package lines and ## annotations
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "# "))
+		module, replacement := fields, []string(nil)
+		for j, field := range fields {
+			if field == "=>" {
+				module, replacement = fields[:j], fields[j+1:]
+				break
+			}
+		}
+		if len(module) == 0 {
+			return nil, fmt.Errorf("malformed line in vendor/modules.txt: %q", line)
+		}
+
+		importPath, name, version := module[0], module[0], ""
+		if len(module) > 1 {
+			version = module[1]
+		}
+		if replacement != nil {
+			if len(replacement) == 0 {
+				return nil, fmt.Errorf("malformed line in vendor/modules.txt: %q", line)
+			}
+
+			name, version = replacement[0], ""
+			if len(replacement) > 1 {
+				version = replacement[1]
+			}
+		}
+
+		// Local file paths
+		if version == "" {
+			version = rootVersion
+		}
+
+		dependencies[importPath] = GoModule{
+			Name:    name,
+			Version: cleanVersion(version),
+		}
 	}
 
 	setGolangDependency(dependencies, thisModule.GoVersion)
@@ -167,6 +258,20 @@ func parseGoListOutput(output, modOutput, rootVersion string) (map[string]GoModu
 	return dependencies, nil
 }
 
+// parseThisModule parses the JSON output of `go list -m` for the module being indexed.
+func parseThisModule(modOutput string) (jsonModule, error) {
+	var thisModule jsonModule
+	if err := json.NewDecoder(strings.NewReader(modOutput)).Decode(&thisModule); err != nil {
+		return jsonModule{}, err
+	}
+
+	if thisModule.GoVersion == "" {
+		return jsonModule{}, errors.New("could not find GoVersion for current module")
+	}
+
+	return thisModule, nil
+}
+
 // The repository to find the source code for golang.
 var golangRepository = "github.com/golang/go"
 
@@ -186,7 +291,9 @@ func GetGolangDependency(dependencies map[string]GoModule) GoModule {
 
 // NormalizeMonikerPackage returns a normalized path to ensure that all
 // standard library paths are handled the same. Primarily to make sure
-// that both the golangRepository and "std/" paths are normalized.
+// that both the golangRepository and "std/" paths are normalized. Paths
+// of packages vendored into a GOPATH project are replaced by the import
+// path of the upstream package.
 func NormalizeMonikerPackage(path string) string {
 	// When indexing _within_ the golang/go repository, `std/` is prefixed
 	// to packages. So we trim that here just to be sure that we keep
@@ -194,6 +301,12 @@ func NormalizeMonikerPackage(path string) string {
 	normalizedPath := strings.TrimPrefix(path, "std/")
 
 	if !isStandardlibPackge(normalizedPath) {
+		// In GOPATH mode, a vendored package is identified by its path
+		// within the vendoring project, e.g. example.com/a/vendor/example.com/b
+		if index := strings.LastIndex(path, "/vendor/"); index >= 0 {
+			return path[index+len("/vendor/"):]
+		}
+
 		return path
 	}
 
@@ -220,7 +333,7 @@ func cleanVersion(version string) string {
 // suffix usable for moniker identifiers. The given root module is used to resolve
 // replace directives with local file paths and is expected to be a resolved import
 // path (a valid URL, including a scheme).
-func resolveImportPaths(rootModule string, modules []string) map[string]string {
+func resolveImportPaths(resolver *Resolver, rootModule string, modules []string) map[string]string {
 	ch := make(chan string, len(modules))
 	for _, module := range modules {
 		ch <- module
@@ -249,7 +362,7 @@ func resolveImportPaths(rootModule string, modules []string) map[string]string {
 				}
 
 				// Determine path suffix relative to the import path
-				resolved, ok := resolveRepoRootForImportPath(name)
+				resolved, ok := resolveRepoRootForImportPath(resolver, name)
 				if !ok {
 					continue
 				}
@@ -265,9 +378,9 @@ func resolveImportPaths(rootModule string, modules []string) map[string]string {
 	return namesToResolve
 }
 
-// resolveRepoRootForImportPath will get the resolved name after handling vsc RepoRoots and any
+// resolveRepoRootForImportPath will get the resolved name after handling repository roots and any
 // necessary handling of the standard library
-func resolveRepoRootForImportPath(name string) (string, bool) {
+func resolveRepoRootForImportPath(resolver *Resolver, name string) (string, bool) {
 	// When indexing golang/go, there are some references to the package "std" itself.
 	//    Generally, "std/" is not referenced directly (it is just assumed when you have "fmt" or similar
 	//    in your imports), but inside of golang/go, it is directly referenced.
@@ -277,9 +390,9 @@ func resolveRepoRootForImportPath(name string) (string, bool) {
 		return name, true
 	}
 
-	repoRoot, err := vcs.RepoRootForImportPath(name, false)
+	repoRoot, err := resolver.RepoRootForImportPath(name)
 	if err != nil {
-		log.Println(fmt.Sprintf("WARNING: Failed to resolve repo %s (%s) %s.", name, err, repoRoot))
+		log.Println(fmt.Sprintf("WARNING: Failed to resolve repo %s (%s).", name, err))
 		return "", false
 	}
 
@@ -87,6 +87,49 @@ func TestParseGoListOutput(t *testing.T) {
 	}
 }
 
+func TestParseVendoredModules(t *testing.T) {
+	modulesTxt := `# github.com/pkg/errors v0.9.1
+## explicit
+github.com/pkg/errors
+# github.com/ghodss/yaml v1.0.0 => github.com/sourcegraph/yaml v1.0.1-0.20200714132230-56936252f152
+## explicit; go 1.12
+github.com/ghodss/yaml
+# github.com/sourcegraph/sourcegraph/lib v0.0.0-00010101000000-000000000000 => ./lib
+## explicit; go 1.16
+github.com/sourcegraph/sourcegraph/lib/errors
+# github.com/sourcegraph/sourcegraph/enterprise/lib => ./enterprise/lib
+`
+
+	modOutput := `
+		{
+			"Path": "github.com/sourcegraph/sourcegraph",
+			"Main": true,
+			"GoVersion": "1.17"
+		}
+	`
+
+	modules, err := parseVendoredModules(modulesTxt, modOutput, "v1.2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]GoModule{
+		"github.com/golang/go":                              {Name: "github.com/golang/go", Version: "go1.17"},
+		"github.com/sourcegraph/sourcegraph":                {Name: "github.com/sourcegraph/sourcegraph", Version: "v1.2.3"},
+		"github.com/pkg/errors":                             {Name: "github.com/pkg/errors", Version: "v0.9.1"},
+		"github.com/ghodss/yaml":                            {Name: "github.com/sourcegraph/yaml", Version: "56936252f152"},
+		"github.com/sourcegraph/sourcegraph/lib":            {Name: "./lib", Version: "v1.2.3"},
+		"github.com/sourcegraph/sourcegraph/enterprise/lib": {Name: "./enterprise/lib", Version: "v1.2.3"},
+	}
+	if diff := cmp.Diff(expected, modules); diff != "" {
+		t.Errorf("unexpected parsed modules (-want +got): %s", diff)
+	}
+
+	if _, err := parseVendoredModules("# example.com/a =>\n", modOutput, "v1.2.3"); err == nil {
+		t.Errorf("expected error for malformed replacement")
+	}
+}
+
 func TestCleanVersion(t *testing.T) {
 	testCases := []struct {
 		input    string
@@ -132,7 +175,7 @@ func TestResolveImportPaths(t *testing.T) {
 		"rsc.io/quote/v3":            "https://github.com/rsc/quote/v3",
 		"./enterprise/lib":           "https://github.com/sourcegraph/sourcegraph/enterprise/lib",
 	}
-	if diff := cmp.Diff(expected, resolveImportPaths("https://github.com/sourcegraph/sourcegraph", modules)); diff != "" {
+	if diff := cmp.Diff(expected, resolveImportPaths(&Resolver{Network: true}, "https://github.com/sourcegraph/sourcegraph", modules)); diff != "" {
 		t.Errorf("unexpected import paths (-want +got): %s", diff)
 	}
 }
@@ -149,6 +192,9 @@ func TestNormalizeMonikerPackage(t *testing.T) {
 
 		// Unknown libs should not be changed (for example, custom proxy)
 		"myCustomPackage": "myCustomPackage",
+
+		// GOPATH vendored packages are named by their upstream import path.
+		"github.com/sourcegraph/lsif-go/vendor/github.com/pkg/errors": "github.com/pkg/errors",
 	}
 
 	for path, expected := range testCases {
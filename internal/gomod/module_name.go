@@ -7,7 +7,6 @@ import (
 
 	"github.com/sourcegraph/lsif-go/internal/command"
 	"github.com/sourcegraph/lsif-go/internal/output"
-	"golang.org/x/tools/go/vcs"
 )
 
 // ModuleName returns the resolved name of the go module declared in the given
@@ -17,7 +16,7 @@ import (
 //
 // isStdLib is true if dir is pointing to the src directory in the golang/go
 // repository.
-func ModuleName(dir, repo string, outputOptions output.Options) (moduleName string, isStdLib bool, err error) {
+func ModuleName(dir, repo string, resolver *Resolver, outputOptions output.Options) (moduleName string, isStdLib bool, err error) {
 	resolve := func() {
 		name := repo
 
@@ -30,7 +29,7 @@ func ModuleName(dir, repo string, outputOptions output.Options) (moduleName stri
 			}
 		}
 
-		moduleName, isStdLib, err = resolveModuleName(repo, name)
+		moduleName, isStdLib, err = resolveModuleName(resolver, repo, name)
 	}
 
 	output.WithProgress("Resolving module name", resolve, outputOptions)
@@ -41,11 +40,11 @@ func ModuleName(dir, repo string, outputOptions output.Options) (moduleName stri
 // representation of a module name usable for moniker identifiers. The base of the
 // import path will be the resolved repository remote, and the given module name
 // is used only to determine the path suffix.
-func resolveModuleName(repo, name string) (string, bool, error) {
+func resolveModuleName(resolver *Resolver, repo, name string) (string, bool, error) {
 	// Determine path suffix relative to repository root
 	var suffix string
 
-	if nameRepoRoot, err := vcs.RepoRootForImportPath(name, false); err == nil {
+	if nameRepoRoot, err := resolver.RepoRootForImportPath(name); err == nil {
 		suffix = strings.TrimPrefix(name, nameRepoRoot.Root)
 	} else {
 		// A user-visible warning will occur on this path as the declared
@@ -53,7 +52,11 @@ func resolveModuleName(repo, name string) (string, bool, error) {
 	}
 
 	// Determine the canonical code host of the current repository
-	repoRepoRoot, err := vcs.RepoRootForImportPath(repo, false)
+	repoRepoRoot, err := resolver.RepoRootForImportPath(repo)
+	if err != nil && !resolver.Network && repo != "" {
+		// The remote names a repository, so without the network it is taken as its own root
+		repoRepoRoot, err = &RepoRoot{Repo: "https://" + repo, Root: repo}, nil
+	}
 	if err != nil {
 		help := "Make sure your git repo has a remote (git remote add origin git@github.com:owner/repo)"
 		return "", false, fmt.Errorf("%v\n\n%s", err, help)
@@ -33,7 +33,7 @@ func TestResolveModuleName(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		if actual, _, err := resolveModuleName(testCase.repo, testCase.name); err != nil {
+		if actual, _, err := resolveModuleName(&Resolver{Network: true}, testCase.repo, testCase.name); err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		} else if actual != testCase.expected {
 			t.Errorf("unexpected module name. want=%q have=%q", testCase.expected, actual)
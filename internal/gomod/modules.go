@@ -0,0 +1,36 @@
+package gomod
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// ListModuleRoots returns the directories under the given root that contain a go.mod file,
+// in lexical order. Directories named testdata or vendor, and directories that begin with
+// a dot or an underscore, are skipped as they are by the go command.
+func ListModuleRoots(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root {
+			if name := d.Name(); name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+		}
+
+		if isModule(path) {
+			dirs = append(dirs, path)
+		}
+
+		return nil
+	})
+
+	return dirs, err
+}
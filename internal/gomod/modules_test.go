@@ -0,0 +1,48 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestListModuleRoots(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		".",
+		"lib",
+		"lib/nested",
+		"tools",
+		"internal/testdata/fixture",
+		"vendor/github.com/test/dep",
+		".cache/mod",
+		"_examples/basic",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), os.ModePerm); err != nil {
+			t.Fatalf("unexpected error creating directory: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module example.com/"+dir+"\n"), 0644); err != nil {
+			t.Fatalf("unexpected error writing go.mod: %s", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "cmd", "noop"), os.ModePerm); err != nil {
+		t.Fatalf("unexpected error creating directory: %s", err)
+	}
+
+	dirs, err := ListModuleRoots(root)
+	if err != nil {
+		t.Fatalf("unexpected error listing modules: %s", err)
+	}
+
+	expected := []string{
+		root,
+		filepath.Join(root, "lib"),
+		filepath.Join(root, "lib", "nested"),
+		filepath.Join(root, "tools"),
+	}
+	if diff := cmp.Diff(expected, dirs); diff != "" {
+		t.Errorf("unexpected module roots (-want +got): %s", diff)
+	}
+}
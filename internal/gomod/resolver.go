@@ -0,0 +1,254 @@
+package gomod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sourcegraph/lsif-go/internal/command"
+	"golang.org/x/tools/go/vcs"
+)
+
+// RepoRoot describes the repository that contains an import path.
+type RepoRoot struct {
+	Repo string // repository URL, including a scheme
+	Root string // import path corresponding to the root of the repository
+}
+
+// Resolver determines the repository that contains an import path. Resolution is attempted
+// with, in order, the user-supplied import map, static rules for well-known code hosts, and
+// the origin recorded for downloaded modules in the module cache. Only if none of these apply
+// and network lookups are enabled will the go-get meta tags of the import path be fetched.
+//
+// Modules replaced by another module in go.mod are resolved by the path of their replacement
+// (see parseGoListOutput), so forks hosted on well-known code hosts resolve offline as well.
+type Resolver struct {
+	ImportMap map[string]string // import path prefix -> repository URL
+	ModCache  string            // module cache directory; empty to skip the module cache
+	Network   bool              // whether import paths may be resolved over the network
+}
+
+// NewResolver creates a resolver with the given import map that reads the module cache of
+// the go command run in the given directory.
+func NewResolver(dir string, importMap map[string]string, network bool) *Resolver {
+	modCache, err := command.Run(dir, "go", "env", "GOMODCACHE")
+	if err != nil {
+		modCache = ""
+	}
+
+	return &Resolver{
+		ImportMap: importMap,
+		ModCache:  modCache,
+		Network:   network,
+	}
+}
+
+// ReadImportMap reads a JSON object mapping import path prefixes to repository URLs from the
+// given file (e.g. {"go.company.com/x": "https://git.company.com/x"}). Repositories without a
+// scheme are assumed to be served over https.
+func ReadImportMap(filename string) (map[string]string, error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules map[string]string
+	if err := json.Unmarshal(contents, &rules); err != nil {
+		return nil, fmt.Errorf("malformed import map %s: %v", filename, err)
+	}
+
+	return normalizeImportMap(rules), nil
+}
+
+// normalizeImportMap removes trailing slashes from the given rules and adds the https scheme
+// to repositories without a scheme.
+func normalizeImportMap(rules map[string]string) map[string]string {
+	importMap := make(map[string]string, len(rules))
+	for prefix, repo := range rules {
+		if !strings.Contains(repo, "://") {
+			repo = "https://" + repo
+		}
+
+		importMap[strings.TrimSuffix(prefix, "/")] = strings.TrimSuffix(repo, "/")
+	}
+
+	return importMap
+}
+
+// RepoRootForImportPath returns the repository containing the given import path.
+func (r *Resolver) RepoRootForImportPath(importPath string) (*RepoRoot, error) {
+	if repoRoot, ok := r.resolveImportMap(importPath); ok {
+		return repoRoot, nil
+	}
+	if repoRoot, ok := resolveKnownHost(importPath); ok {
+		return repoRoot, nil
+	}
+	if repoRoot, ok := r.resolveModuleCache(importPath); ok {
+		return repoRoot, nil
+	}
+
+	if !r.Network {
+		return nil, fmt.Errorf("no offline rule matches %s and network lookups are disabled", importPath)
+	}
+
+	repoRoot, err := vcs.RepoRootForImportPath(importPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RepoRoot{Repo: repoRoot.Repo, Root: repoRoot.Root}, nil
+}
+
+// resolveImportMap resolves the given import path with the longest matching prefix of the
+// import map. Prefixes match whole path elements only.
+func (r *Resolver) resolveImportMap(importPath string) (*RepoRoot, bool) {
+	longest := ""
+	for prefix := range r.ImportMap {
+		if len(prefix) > len(longest) && hasPathPrefix(importPath, prefix) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return nil, false
+	}
+
+	return &RepoRoot{Repo: r.ImportMap[longest], Root: longest}, true
+}
+
+// hasPathPrefix returns true if the given prefix is equal to or a parent of the given path.
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+var gopkgInVersionPattern = regexp.MustCompile(`\.v\d+$`)
+
+// resolveKnownHost resolves import paths of code hosts whose repositories can be determined
+// from the import path alone. The results match those of the go-get protocol for these hosts.
+func resolveKnownHost(importPath string) (*RepoRoot, bool) {
+	parts := strings.Split(importPath, "/")
+
+	var repo string
+	var elements int
+	switch parts[0] {
+	case "github.com", "bitbucket.org":
+		// github.com/owner/repo
+		elements = 3
+	case "gopkg.in":
+		// gopkg.in/pkg.v1 or gopkg.in/owner/pkg.v1
+		elements = 2
+		if len(parts) > 1 && !gopkgInVersionPattern.MatchString(parts[1]) {
+			elements = 3
+		}
+	case "golang.org":
+		// golang.org/x/repo, hosted on go.googlesource.com/repo
+		if len(parts) < 3 || parts[1] != "x" {
+			return nil, false
+		}
+		elements = 3
+		repo = "https://go.googlesource.com/" + parts[2]
+	default:
+		return nil, false
+	}
+
+	if len(parts) < elements {
+		return nil, false
+	}
+
+	root := strings.Join(parts[:elements], "/")
+	if repo == "" {
+		repo = "https://" + root
+	}
+
+	return &RepoRoot{Repo: repo, Root: root}, true
+}
+
+var majorVersionSuffixPattern = regexp.MustCompile(`/v[2-9][0-9]*$`)
+
+// resolveModuleCache resolves the given import path with the origin that the go command
+// records for downloaded modules (go1.19+). Each parent path of the import path is looked
+// up as a module path, from longest to shortest.
+func (r *Resolver) resolveModuleCache(importPath string) (*RepoRoot, bool) {
+	if r.ModCache == "" {
+		return nil, false
+	}
+
+	for modulePath := importPath; strings.Contains(modulePath, "/"); modulePath = path.Dir(modulePath) {
+		origin, ok := r.readModuleOrigin(modulePath)
+		if !ok {
+			continue
+		}
+
+		// The root of the repository is the module path without the directory of the module
+		// within the repository and without a major version suffix, which is either part of
+		// that directory or denotes a major version branch or tag.
+		root := modulePath
+		if origin.Subdir == "" || !strings.HasSuffix(root, "/"+origin.Subdir) {
+			root = majorVersionSuffixPattern.ReplaceAllString(root, "")
+		}
+		if origin.Subdir != "" {
+			root = strings.TrimSuffix(root, "/"+origin.Subdir)
+		}
+
+		return &RepoRoot{Repo: strings.TrimSuffix(origin.URL, "/"), Root: root}, true
+	}
+
+	return nil, false
+}
+
+type moduleOrigin struct {
+	VCS    string `json:"VCS"`
+	URL    string `json:"URL"`
+	Subdir string `json:"Subdir"`
+}
+
+// readModuleOrigin returns the origin of any downloaded version of the given module.
+func (r *Resolver) readModuleOrigin(modulePath string) (moduleOrigin, bool) {
+	dir := filepath.Join(r.ModCache, "cache", "download", filepath.FromSlash(escapeModulePath(modulePath)), "@v")
+
+	infoFiles, err := filepath.Glob(filepath.Join(dir, "*.info"))
+	if err != nil {
+		return moduleOrigin{}, false
+	}
+
+	// Prefer the origin of later versions, should the module have moved
+	sort.Sort(sort.Reverse(sort.StringSlice(infoFiles)))
+
+	for _, infoFile := range infoFiles {
+		contents, err := os.ReadFile(infoFile)
+		if err != nil {
+			continue
+		}
+
+		var info struct {
+			Origin *moduleOrigin `json:"Origin"`
+		}
+		if err := json.Unmarshal(contents, &info); err != nil || info.Origin == nil || info.Origin.URL == "" {
+			continue
+		}
+
+		return *info.Origin, true
+	}
+
+	return moduleOrigin{}, false
+}
+
+// escapeModulePath returns the path of the given module within the module cache, in which
+// each upper-case letter is replaced by an exclamation mark followed by its lower-case form.
+func escapeModulePath(modulePath string) string {
+	var b strings.Builder
+	for _, r := range modulePath {
+		if unicode.IsUpper(r) {
+			b.WriteRune('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
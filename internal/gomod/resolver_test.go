@@ -0,0 +1,121 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolverOffline(t *testing.T) {
+	modCache := t.TempDir()
+	writeModuleInfo(t, modCache, "cloud.google.com/go/pubsub", "v1.3.1", `{"Version":"v1.3.1","Origin":{"VCS":"git","URL":"https://github.com/googleapis/google-cloud-go","Subdir":"pubsub"}}`)
+	writeModuleInfo(t, modCache, "go.uber.org/zap", "v1.16.0", `{"Version":"v1.16.0","Origin":{"VCS":"git","URL":"https://github.com/uber-go/zap"}}`)
+	writeModuleInfo(t, modCache, "rsc.io/quote/v3", "v3.1.0", `{"Version":"v3.1.0","Origin":{"VCS":"git","URL":"https://github.com/rsc/quote"}}`)
+	writeModuleInfo(t, modCache, "k8s.io/klog", "v1.0.0", `{"Version":"v1.0.0"}`)
+
+	resolver := &Resolver{
+		ImportMap: map[string]string{
+			"go.company.com":   "https://git.company.com/go",
+			"go.company.com/x": "https://git.company.com/x",
+		},
+		ModCache: modCache,
+	}
+
+	modules := []string{
+		"bitbucket.org/owner/repo/sub",
+		"cloud.google.com/go/pubsub",
+		"github.com/etcd-io/bbolt",
+		"go.company.com/lib",
+		"go.company.com/x/sub",
+		"go.company.com/xy",
+		"go.uber.org/zap",
+		"golang.org/x/crypto",
+		"gopkg.in/inf.v0",
+		"gopkg.in/owner/pkg.v1",
+		"k8s.io/klog",
+		"rsc.io/quote/v3",
+		"./enterprise/lib",
+	}
+
+	expected := map[string]string{
+		"bitbucket.org/owner/repo/sub": "https://bitbucket.org/owner/repo/sub",
+		"cloud.google.com/go/pubsub":   "https://github.com/googleapis/google-cloud-go/pubsub",
+		"github.com/etcd-io/bbolt":     "https://github.com/etcd-io/bbolt",
+		"go.company.com/lib":           "https://git.company.com/go/lib",
+		"go.company.com/x/sub":         "https://git.company.com/x/sub",
+		"go.company.com/xy":            "https://git.company.com/go/xy",
+		"go.uber.org/zap":              "https://github.com/uber-go/zap",
+		"golang.org/x/crypto":          "https://go.googlesource.com/crypto",
+		"gopkg.in/inf.v0":              "https://gopkg.in/inf.v0",
+		"gopkg.in/owner/pkg.v1":        "https://gopkg.in/owner/pkg.v1",
+		"rsc.io/quote/v3":              "https://github.com/rsc/quote/v3",
+		"./enterprise/lib":             "https://github.com/sourcegraph/sourcegraph/enterprise/lib",
+	}
+	if diff := cmp.Diff(expected, resolveImportPaths(resolver, "https://github.com/sourcegraph/sourcegraph", modules)); diff != "" {
+		t.Errorf("unexpected import paths (-want +got): %s", diff)
+	}
+}
+
+func TestResolveModuleNameOffline(t *testing.T) {
+	resolver := &Resolver{
+		ImportMap: map[string]string{"go.company.com/x": "https://git.company.com/x"},
+	}
+
+	testCases := []struct {
+		repo     string
+		name     string
+		expected string
+	}{
+		{
+			repo:     "github.com/sourcegraph/zoekt",
+			name:     "github.com/google/zoekt/some/sub/path",
+			expected: "https://github.com/sourcegraph/zoekt/some/sub/path",
+		},
+		{
+			repo:     "git.company.com/x",
+			name:     "go.company.com/x/lib",
+			expected: "https://git.company.com/x/lib",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if actual, _, err := resolveModuleName(resolver, testCase.repo, testCase.name); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		} else if actual != testCase.expected {
+			t.Errorf("unexpected module name. want=%q have=%q", testCase.expected, actual)
+		}
+	}
+}
+
+func TestReadImportMap(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "import-map.json")
+	if err := os.WriteFile(filename, []byte(`{"go.company.com/x/": "git.company.com/x/", "go.company.com/y": "ssh://git.company.com/y"}`), 0644); err != nil {
+		t.Fatalf("unexpected error writing import map: %s", err)
+	}
+
+	importMap, err := ReadImportMap(filename)
+	if err != nil {
+		t.Fatalf("unexpected error reading import map: %s", err)
+	}
+
+	expected := map[string]string{
+		"go.company.com/x": "https://git.company.com/x",
+		"go.company.com/y": "ssh://git.company.com/y",
+	}
+	if diff := cmp.Diff(expected, importMap); diff != "" {
+		t.Errorf("unexpected import map (-want +got): %s", diff)
+	}
+}
+
+func writeModuleInfo(t *testing.T, modCache, modulePath, version, info string) {
+	dir := filepath.Join(modCache, "cache", "download", filepath.FromSlash(escapeModulePath(modulePath)), "@v")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("unexpected error creating module cache: %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, version+".info"), []byte(info), 0644); err != nil {
+		t.Fatalf("unexpected error writing module info: %s", err)
+	}
+}
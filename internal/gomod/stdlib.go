@@ -2,17 +2,10 @@
 // Generated by: go version go1.18.2 darwin/amd64
 package gomod
 
-// isStandardlibPackge determines whether a package is in the standard library
-// or not. At this point, it checks whether the package name is one of those
-// that is found from running "go list std" in the latest released go version.
-func isStandardlibPackge(pkg string) bool {
-	_, ok := standardLibraryMap[pkg]
-	return ok
-}
-
 var contained = struct{}{}
 
-// This list is calculated from "go list std".
+// This list is calculated from "go list std". It is consulted only when the
+// standard library packages of the active Go toolchain cannot be listed.
 var standardLibraryMap = map[string]interface{}{
 	"archive/tar":                          contained,
 	"archive/zip":                          contained,
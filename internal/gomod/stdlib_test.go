@@ -1,6 +1,10 @@
 package gomod
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestStdLib(t *testing.T) {
 	expectedStdlib := []string{
@@ -27,3 +31,33 @@ func TestStdLib(t *testing.T) {
 		}
 	}
 }
+
+func TestListStandardLibraryCache(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	packages, err := listStandardLibrary(cacheDir)
+	if err != nil {
+		t.Fatalf("unexpected error listing standard library: %s", err)
+	}
+	if _, ok := packages["fmt"]; !ok {
+		t.Errorf(`"fmt" should be listed as a standard library package`)
+	}
+
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+	if err != nil || len(cacheFiles) != 1 {
+		t.Fatalf("expected one cache file. have=%v", cacheFiles)
+	}
+
+	// Subsequent listings for the same go version are read from the cache
+	if err := os.WriteFile(cacheFiles[0], []byte("fmt\nexample/fake\n"), 0644); err != nil {
+		t.Fatalf("unexpected error writing cache file: %s", err)
+	}
+
+	packages, err = listStandardLibrary(cacheDir)
+	if err != nil {
+		t.Fatalf("unexpected error listing standard library: %s", err)
+	}
+	if _, ok := packages["example/fake"]; !ok {
+		t.Errorf(`"example/fake" should be read from the cache`)
+	}
+}
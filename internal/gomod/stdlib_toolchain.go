@@ -0,0 +1,94 @@
+package gomod
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/lsif-go/internal/command"
+)
+
+// isStandardlibPackge determines whether a package is in the standard library of the active
+// Go toolchain. If the toolchain cannot be queried, the package is looked up in the list that
+// was generated from the latest released go version at the time (see standardLibraryMap).
+func isStandardlibPackge(pkg string) bool {
+	packages := toolchainStandardLibrary()
+	if packages == nil {
+		packages = standardLibraryMap
+	}
+
+	_, ok := packages[pkg]
+	return ok
+}
+
+var (
+	toolchainStandardLibraryOnce sync.Once
+	toolchainStandardLibraryMap  map[string]interface{}
+)
+
+// toolchainStandardLibrary returns the standard library packages of the active Go toolchain,
+// or nil if they cannot be listed. The packages are listed at most once per process.
+func toolchainStandardLibrary() map[string]interface{} {
+	toolchainStandardLibraryOnce.Do(func() {
+		cacheDir, err := os.UserCacheDir()
+		if err == nil {
+			cacheDir = filepath.Join(cacheDir, "lsif-go", "stdlib")
+		}
+
+		if toolchainStandardLibraryMap, err = listStandardLibrary(cacheDir); err != nil {
+			log.Printf("WARNING: Failed to list standard library packages (%s). Falling back to the packages of go1.18.", err)
+		}
+	})
+
+	return toolchainStandardLibraryMap
+}
+
+var unsafeFilenameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// listStandardLibrary returns the packages listed by `go list std`. The list is cached in a
+// file named after the version of the toolchain in the given directory, if it is non-empty.
+func listStandardLibrary(cacheDir string) (map[string]interface{}, error) {
+	goVersion, err := command.Run("", "go", "env", "GOVERSION")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine go version: %v\n%s", err, goVersion)
+	}
+
+	var cacheFile string
+	if cacheDir != "" && goVersion != "" {
+		cacheFile = filepath.Join(cacheDir, unsafeFilenameCharacters.ReplaceAllString(goVersion, "_"))
+
+		if contents, err := os.ReadFile(cacheFile); err == nil {
+			return parseStandardLibrary(string(contents)), nil
+		}
+	}
+
+	out, err := command.Run("", "go", "list", "std")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list standard library: %v\n%s", err, out)
+	}
+
+	if cacheFile != "" {
+		// The cache is an optimization, so failing to write it is not an error
+		if err := os.MkdirAll(cacheDir, os.ModePerm); err == nil {
+			_ = os.WriteFile(cacheFile, []byte(out+"\n"), 0644)
+		}
+	}
+
+	return parseStandardLibrary(out), nil
+}
+
+// parseStandardLibrary parses the output of `go list std`.
+func parseStandardLibrary(output string) map[string]interface{} {
+	packages := map[string]interface{}{}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			packages[line] = contained
+		}
+	}
+
+	return packages
+}
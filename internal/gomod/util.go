@@ -10,3 +10,16 @@ func isModule(dir string) bool {
 	_, err := os.Stat(filepath.Join(dir, "go.mod"))
 	return err == nil
 }
+
+// IsWorkspace returns true if there is a go.work file in the given directory.
+func IsWorkspace(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.work"))
+	return err == nil
+}
+
+// isVendored returns true if the module in the given directory is built from its vendor
+// directory, which the go command does by default when vendor/modules.txt exists.
+func isVendored(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "vendor", "modules.txt"))
+	return err == nil
+}
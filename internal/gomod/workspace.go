@@ -0,0 +1,92 @@
+package gomod
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/command"
+	"github.com/sourcegraph/lsif-go/internal/output"
+)
+
+// WorkspaceModule is a module listed by a use directive of a go.work file.
+type WorkspaceModule struct {
+	Path string // declared module path
+	Name string // resolved module name usable for moniker identifiers
+	Dir  string // absolute path of the directory containing the go.mod file
+}
+
+// ListWorkspaceModules returns the modules of the go.work workspace in the given directory,
+// ordered by directory. The name of each module is resolved in the same way as ModuleName
+// resolves the name of a single module.
+func ListWorkspaceModules(dir, repo string, resolver *Resolver, outputOptions output.Options) (modules []WorkspaceModule, err error) {
+	if !IsWorkspace(dir) {
+		return nil, fmt.Errorf("no go.work file found in %s", dir)
+	}
+
+	resolve := func() {
+		// In workspace mode, the main modules are the modules of the workspace
+		out, runErr := command.Run(dir, "go", "list", "-m", "-json")
+		if runErr != nil {
+			err = fmt.Errorf("failed to list workspace modules: %v\n%s", runErr, out)
+			return
+		}
+
+		if modules, err = parseWorkspaceModules(out); err != nil {
+			return
+		}
+
+		for i, module := range modules {
+			if modules[i].Name, _, err = resolveModuleName(resolver, repo, module.Path); err != nil {
+				return
+			}
+		}
+	}
+
+	output.WithProgress("Listing workspace modules", resolve, outputOptions)
+	return modules, err
+}
+
+// parseWorkspaceModules parses the JSON output of `go list -m` run in workspace mode.
+func parseWorkspaceModules(output string) ([]WorkspaceModule, error) {
+	var modules []WorkspaceModule
+	decoder := json.NewDecoder(strings.NewReader(output))
+
+	for {
+		var module struct {
+			Path string `json:"Path"`
+			Dir  string `json:"Dir"`
+		}
+		if err := decoder.Decode(&module); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, err
+		}
+
+		modules = append(modules, WorkspaceModule{Path: module.Path, Dir: module.Dir})
+	}
+
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
+
+// WorkspacePatterns returns a package pattern for each of the given modules that selects
+// the packages of the module relative to the given directory (e.g. ./lib/...).
+func WorkspacePatterns(dir string, modules []WorkspaceModule) []string {
+	patterns := make([]string, 0, len(modules))
+	for _, module := range modules {
+		relative, err := filepath.Rel(dir, module.Dir)
+		if err != nil {
+			continue
+		}
+
+		patterns = append(patterns, "./"+filepath.ToSlash(filepath.Join(relative, "...")))
+	}
+
+	return patterns
+}
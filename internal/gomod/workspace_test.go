@@ -0,0 +1,52 @@
+package gomod
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWorkspaceModules(t *testing.T) {
+	output := `
+		{
+			"Path": "github.com/test/repo/tools",
+			"Main": true,
+			"Dir": "/dev/repo/tools",
+			"GoMod": "/dev/repo/tools/go.mod",
+			"GoVersion": "1.18"
+		}
+		{
+			"Path": "github.com/test/repo",
+			"Main": true,
+			"Dir": "/dev/repo",
+			"GoMod": "/dev/repo/go.mod",
+			"GoVersion": "1.18"
+		}
+	`
+
+	modules, err := parseWorkspaceModules(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []WorkspaceModule{
+		{Path: "github.com/test/repo", Dir: "/dev/repo"},
+		{Path: "github.com/test/repo/tools", Dir: "/dev/repo/tools"},
+	}
+	if diff := cmp.Diff(expected, modules); diff != "" {
+		t.Errorf("unexpected modules (-want +got): %s", diff)
+	}
+}
+
+func TestWorkspacePatterns(t *testing.T) {
+	modules := []WorkspaceModule{
+		{Path: "github.com/test/repo", Dir: "/dev/repo"},
+		{Path: "github.com/test/repo/tools", Dir: "/dev/repo/tools"},
+		{Path: "github.com/test/repo/lib/v2", Dir: "/dev/repo/lib"},
+	}
+
+	expected := []string{"./...", "./tools/...", "./lib/..."}
+	if diff := cmp.Diff(expected, WorkspacePatterns("/dev/repo", modules)); diff != "" {
+		t.Errorf("unexpected patterns (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,350 @@
+package indexer
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sourcegraph/lsif-go/internal/output"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	atomicpass "golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/buildtag"
+	"golang.org/x/tools/go/analysis/passes/cgocall"
+	"golang.org/x/tools/go/analysis/passes/composite"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/framepointer"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/testinggoroutine"
+	"golang.org/x/tools/go/analysis/passes/tests"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unsafeptr"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/packages"
+)
+
+// analyzerSuites maps the names of sets of analyzers to their members. Besides the names
+// of these suites, ParseAnalyzers accepts the name of any of their members.
+var analyzerSuites = map[string][]*analysis.Analyzer{
+	// The analyzers run by `go vet`
+	"vet": {
+		asmdecl.Analyzer,
+		assign.Analyzer,
+		atomicpass.Analyzer,
+		bools.Analyzer,
+		buildtag.Analyzer,
+		cgocall.Analyzer,
+		composite.Analyzer,
+		copylock.Analyzer,
+		errorsas.Analyzer,
+		framepointer.Analyzer,
+		httpresponse.Analyzer,
+		ifaceassert.Analyzer,
+		loopclosure.Analyzer,
+		lostcancel.Analyzer,
+		nilfunc.Analyzer,
+		printf.Analyzer,
+		shift.Analyzer,
+		sigchanyzer.Analyzer,
+		stdmethods.Analyzer,
+		stringintconv.Analyzer,
+		structtag.Analyzer,
+		testinggoroutine.Analyzer,
+		tests.Analyzer,
+		unmarshal.Analyzer,
+		unreachable.Analyzer,
+		unsafeptr.Analyzer,
+		unusedresult.Analyzer,
+	},
+}
+
+// ParseAnalyzers parses a comma-separated list of analyzer suites and analyzer names
+// (e.g. vet or printf,copylocks). Analyzers named more than once are run once.
+func ParseAnalyzers(s string) ([]*analysis.Analyzer, error) {
+	analyzersByName := map[string]*analysis.Analyzer{}
+	for _, suite := range analyzerSuites {
+		for _, analyzer := range suite {
+			analyzersByName[analyzer.Name] = analyzer
+		}
+	}
+
+	var analyzers []*analysis.Analyzer
+	seen := map[*analysis.Analyzer]struct{}{}
+	add := func(analyzer *analysis.Analyzer) {
+		if _, ok := seen[analyzer]; !ok {
+			seen[analyzer] = struct{}{}
+			analyzers = append(analyzers, analyzer)
+		}
+	}
+
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		if suite, ok := analyzerSuites[name]; ok {
+			for _, analyzer := range suite {
+				add(analyzer)
+			}
+		} else if analyzer, ok := analyzersByName[name]; ok {
+			add(analyzer)
+		} else {
+			return nil, fmt.Errorf("unknown analyzer %q", name)
+		}
+	}
+
+	return analyzers, nil
+}
+
+// analysisFinding is a diagnostic reported by an analyzer, resolved to file positions.
+type analysisFinding struct {
+	Analyzer string
+	Category string
+	Start    token.Position
+	End      token.Position // invalid if the diagnostic has no end
+	Message  string
+}
+
+// runAnalyzers runs the configured analyzers over the project packages and stores their
+// diagnostics for emitDiagnostics. The analyzers reuse the syntax and type information of
+// the loaded packages. Packages are analyzed after the project packages they import so that
+// facts exported about their objects are visible to importers; facts about packages outside
+// of the project are not available, as those packages are not analyzed.
+func (i *Indexer) runAnalyzers() {
+	if len(i.generationOptions.Analyzers) == 0 {
+		return
+	}
+
+	pkgs := analysisOrder(i.packages)
+	facts := factStore{}
+
+	var count uint64
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for _, p := range pkgs {
+			if i.outputOptions.Verbosity >= output.VeryVerboseOutput {
+				log.Printf("\tPackage %s", p.ID)
+			}
+
+			i.analyzePackage(p, facts)
+			atomic.AddUint64(&count, 1)
+		}
+	}()
+
+	output.WithProgressParallel(&wg, "Running analyzers", i.outputOptions, &count, uint64(len(pkgs)))
+}
+
+// analysisOrder returns the given packages ordered such that every package follows the
+// packages of the given set that it imports.
+func analysisOrder(pkgs []*packages.Package) []*packages.Package {
+	project := make(map[*packages.Package]struct{}, len(pkgs))
+	for _, p := range pkgs {
+		project[p] = struct{}{}
+	}
+
+	ordered := make([]*packages.Package, 0, len(pkgs))
+	visited := map[*packages.Package]struct{}{}
+
+	var visit func(p *packages.Package)
+	visit = func(p *packages.Package) {
+		if _, ok := visited[p]; ok {
+			return
+		}
+		visited[p] = struct{}{}
+
+		for _, importPath := range sortedKeys(p.Imports) {
+			if dep := p.Imports[importPath]; dep != nil {
+				if _, ok := project[dep]; ok {
+					visit(dep)
+				}
+			}
+		}
+
+		ordered = append(ordered, p)
+	}
+
+	for _, p := range pkgs {
+		visit(p)
+	}
+
+	return ordered
+}
+
+// analyzePackage runs the configured analyzers and the analyzers they require over the
+// given package. Only the diagnostics of the configured analyzers are recorded. Analyzers
+// that fail are skipped with a warning, as are the analyzers that require them. Ill-typed
+// packages are only analyzed by analyzers that tolerate type errors.
+func (i *Indexer) analyzePackage(p *packages.Package, facts factStore) {
+	requested := make(map[*analysis.Analyzer]struct{}, len(i.generationOptions.Analyzers))
+	for _, analyzer := range i.generationOptions.Analyzers {
+		requested[analyzer] = struct{}{}
+	}
+
+	results := map[*analysis.Analyzer]interface{}{}
+	succeeded := map[*analysis.Analyzer]bool{}
+
+	var run func(analyzer *analysis.Analyzer) bool
+	run = func(analyzer *analysis.Analyzer) bool {
+		if ok, done := succeeded[analyzer]; done {
+			return ok
+		}
+		succeeded[analyzer] = false
+
+		resultOf := make(map[*analysis.Analyzer]interface{}, len(analyzer.Requires))
+		for _, required := range analyzer.Requires {
+			if !run(required) {
+				return false
+			}
+			resultOf[required] = results[required]
+		}
+
+		if p.IllTyped && !analyzer.RunDespiteErrors {
+			return false
+		}
+
+		pass := &analysis.Pass{
+			Analyzer:   analyzer,
+			Fset:       p.Fset,
+			Files:      p.Syntax,
+			OtherFiles: p.OtherFiles,
+			Pkg:        p.Types,
+			TypesInfo:  p.TypesInfo,
+			TypesSizes: p.TypesSizes,
+			ResultOf:   resultOf,
+			Report: func(d analysis.Diagnostic) {
+				if _, ok := requested[analyzer]; ok {
+					i.recordAnalysisFinding(p, analyzer, d)
+				}
+			},
+		}
+		facts.bind(pass, p.Types)
+
+		result, err := runAnalyzer(analyzer, pass)
+		if err != nil {
+			if i.outputOptions.Verbosity != output.NoOutput {
+				log.Printf("WARNING: Analyzer %s failed on package %s: %s", analyzer.Name, p.ID, err)
+			}
+
+			return false
+		}
+
+		results[analyzer] = result
+		succeeded[analyzer] = true
+		return true
+	}
+
+	for _, analyzer := range i.generationOptions.Analyzers {
+		run(analyzer)
+	}
+}
+
+// runAnalyzer runs the given analyzer pass. A panicking analyzer is reported as an error
+// so that one misbehaving analyzer does not abort the index.
+func runAnalyzer(analyzer *analysis.Analyzer, pass *analysis.Pass) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return analyzer.Run(pass)
+}
+
+// recordAnalysisFinding stores the given diagnostic of an analyzer run over the given package.
+func (i *Indexer) recordAnalysisFinding(p *packages.Package, analyzer *analysis.Analyzer, d analysis.Diagnostic) {
+	finding := analysisFinding{
+		Analyzer: analyzer.Name,
+		Category: d.Category,
+		Start:    p.Fset.Position(d.Pos),
+		Message:  d.Message,
+	}
+	if d.End.IsValid() && d.End > d.Pos {
+		finding.End = p.Fset.Position(d.End)
+	}
+
+	i.analysisFindings = append(i.analysisFindings, finding)
+}
+
+// factKey identifies a fact exported by an analyzer about an object or, if object is nil,
+// about a package.
+type factKey struct {
+	analyzer *analysis.Analyzer
+	object   types.Object
+	pkg      *types.Package
+	factType reflect.Type
+}
+
+// factStore holds the facts exported by the analyzers across all analyzed packages.
+type factStore map[factKey]analysis.Fact
+
+// bind sets the fact functions of the given pass, which analyzes the given package.
+func (s factStore) bind(pass *analysis.Pass, pkg *types.Package) {
+	analyzer := pass.Analyzer
+
+	importFact := func(key factKey, fact analysis.Fact) bool {
+		stored, ok := s[key]
+		if ok {
+			reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+		}
+
+		return ok
+	}
+
+	pass.ImportObjectFact = func(obj types.Object, fact analysis.Fact) bool {
+		return importFact(factKey{analyzer: analyzer, object: obj, factType: reflect.TypeOf(fact)}, fact)
+	}
+	pass.ImportPackageFact = func(pkg *types.Package, fact analysis.Fact) bool {
+		return importFact(factKey{analyzer: analyzer, pkg: pkg, factType: reflect.TypeOf(fact)}, fact)
+	}
+	pass.ExportObjectFact = func(obj types.Object, fact analysis.Fact) {
+		s[factKey{analyzer: analyzer, object: obj, factType: reflect.TypeOf(fact)}] = fact
+	}
+	pass.ExportPackageFact = func(fact analysis.Fact) {
+		s[factKey{analyzer: analyzer, pkg: pkg, factType: reflect.TypeOf(fact)}] = fact
+	}
+
+	pass.AllObjectFacts = func() (facts []analysis.ObjectFact) {
+		for key, fact := range s {
+			if key.analyzer == analyzer && key.object != nil {
+				facts = append(facts, analysis.ObjectFact{Object: key.object, Fact: fact})
+			}
+		}
+
+		sort.Slice(facts, func(i, j int) bool { return facts[i].Object.Pos() < facts[j].Object.Pos() })
+		return facts
+	}
+	pass.AllPackageFacts = func() (facts []analysis.PackageFact) {
+		for key, fact := range s {
+			if key.analyzer == analyzer && key.object == nil {
+				facts = append(facts, analysis.PackageFact{Package: key.pkg, Fact: fact})
+			}
+		}
+
+		sort.Slice(facts, func(i, j int) bool { return facts[i].Package.Path() < facts[j].Package.Path() })
+		return facts
+	}
+}
@@ -0,0 +1,196 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestParseAnalyzers(t *testing.T) {
+	analyzers, err := ParseAnalyzers("printf, vet,copylocks,")
+	if err != nil {
+		t.Fatalf("unexpected error parsing analyzers: %s", err)
+	}
+
+	if len(analyzers) != len(analyzerSuites["vet"]) {
+		t.Errorf("unexpected number of analyzers. want=%d have=%d", len(analyzerSuites["vet"]), len(analyzers))
+	}
+	if analyzers[0].Name != "printf" {
+		t.Errorf("unexpected first analyzer. want=%q have=%q", "printf", analyzers[0].Name)
+	}
+
+	if analyzers, err := ParseAnalyzers(""); err != nil || len(analyzers) != 0 {
+		t.Errorf("unexpected analyzers for empty input: %v (%v)", analyzers, err)
+	}
+
+	if _, err := ParseAnalyzers("vet,staticcheck"); err == nil {
+		t.Errorf("expected error parsing unknown analyzer")
+	}
+}
+
+// deprecatedFact marks functions whose name starts with Old.
+type deprecatedFact struct{ Replacement string }
+
+func (*deprecatedFact) AFact() {}
+
+// callsAnalyzer returns the calls of a package. Its diagnostics must not be emitted, as
+// it only runs as a requirement of deprecatedAnalyzer.
+var callsAnalyzer = &analysis.Analyzer{
+	Name:       "calls",
+	ResultType: reflect.TypeOf([]*ast.CallExpr{}),
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		var calls []*ast.CallExpr
+		for _, f := range pass.Files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					calls = append(calls, call)
+					pass.Report(analysis.Diagnostic{Pos: call.Pos(), Message: "call"})
+				}
+				return true
+			})
+		}
+
+		return calls, nil
+	},
+}
+
+var deprecatedAnalyzer = &analysis.Analyzer{
+	Name:      "deprecated",
+	Requires:  []*analysis.Analyzer{callsAnalyzer},
+	FactTypes: []analysis.Fact{new(deprecatedFact)},
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		for _, name := range pass.Pkg.Scope().Names() {
+			if strings.HasPrefix(name, "Old") {
+				pass.ExportObjectFact(pass.Pkg.Scope().Lookup(name), &deprecatedFact{Replacement: strings.TrimPrefix(name, "Old")})
+			}
+		}
+
+		for _, call := range pass.ResultOf[callsAnalyzer].([]*ast.CallExpr) {
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+
+			var fact deprecatedFact
+			if pass.ImportObjectFact(pass.TypesInfo.Uses[selector.Sel], &fact) {
+				pass.Report(analysis.Diagnostic{
+					Pos:      selector.Sel.Pos(),
+					End:      selector.Sel.End(),
+					Category: "deprecation",
+					Message:  "use " + fact.Replacement,
+				})
+			}
+		}
+
+		return nil, nil
+	},
+}
+
+var panickingAnalyzer = &analysis.Analyzer{
+	Name: "panicking",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		panic("oops")
+	},
+}
+
+func TestRunAnalyzers(t *testing.T) {
+	dir := t.TempDir()
+	fset := token.NewFileSet()
+
+	dep := loadTestPackage(t, fset, filepath.Join(dir, "dep.go"), "example.com/dep", "package dep\n\nfunc OldThing() {}\n\nfunc Thing() {}\n", nil)
+	main := loadTestPackage(t, fset, filepath.Join(dir, "main.go"), "example.com/main", "package main\n\nimport \"example.com/dep\"\n\nfunc main() {\n\tdep.OldThing()\n\tdep.Thing()\n}\n", dep)
+
+	w := &capturingWriter{}
+	indexer := &Indexer{
+		emitter:   NewJSONEmitter(w),
+		documents: map[string]*DocumentInfo{filepath.Join(dir, "main.go"): {DocumentID: 42}},
+		// Importers are analyzed after their dependencies regardless of this order
+		packages: []*packages.Package{main, dep},
+		generationOptions: GenerationOptions{
+			Analyzers: []*analysis.Analyzer{panickingAnalyzer, deprecatedAnalyzer},
+		},
+	}
+
+	indexer.runAnalyzers()
+	indexer.emitDiagnostics()
+
+	expected := []interface{}{
+		lsif.NewDiagnosticResult(1, []lsif.Diagnostic{
+			{
+				Range:    lsif.Range{Start: protocol.Pos{Line: 5, Character: 5}, End: protocol.Pos{Line: 5, Character: 13}},
+				Severity: lsif.SeverityWarning,
+				Code:     "deprecation",
+				Source:   "deprecated",
+				Message:  "use Thing",
+			},
+		}),
+		lsif.NewTextDocumentDiagnostic(2, 42, 1),
+	}
+	if diff := cmp.Diff(expected, w.elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+}
+
+// loadTestPackage parses and type checks a single-file package as packages.Load would with
+// the load mode of the indexer. The given dependency, if any, is the only importable package.
+func loadTestPackage(t *testing.T, fset *token.FileSet, filename, pkgPath, source string, dep *packages.Package) *packages.Package {
+	if err := os.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatalf("unexpected error writing file: %s", err)
+	}
+
+	f, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err)
+	}
+
+	imports := map[string]*packages.Package{}
+	if dep != nil {
+		imports[dep.PkgPath] = dep
+	}
+
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	config := &types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if p, ok := imports[path]; ok {
+			return p.Types, nil
+		}
+		return importer.Default().Import(path)
+	})}
+
+	pkg, err := config.Check(pkgPath, fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatalf("unexpected error type checking package: %s", err)
+	}
+
+	return &packages.Package{
+		ID:        pkgPath,
+		Name:      pkg.Name(),
+		PkgPath:   pkgPath,
+		GoFiles:   []string{filename},
+		Imports:   imports,
+		Types:     pkg,
+		Fset:      fset,
+		Syntax:    []*ast.File{f},
+		TypesInfo: info,
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"strings"
+)
+
+// BuildConfig is a target platform for which the project packages are loaded. The zero
+// value denotes the platform of the go command running the indexer.
+type BuildConfig struct {
+	GOOS   string
+	GOARCH string
+}
+
+func (c BuildConfig) String() string {
+	return c.GOOS + "/" + c.GOARCH
+}
+
+// ParseBuildConfigs parses a comma-separated list of GOOS/GOARCH pairs
+// (e.g. linux/amd64,windows/amd64).
+func ParseBuildConfigs(s string) ([]BuildConfig, error) {
+	var configs []BuildConfig
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		parts := strings.Split(pair, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("malformed build configuration %q: expected GOOS/GOARCH", pair)
+		}
+
+		configs = append(configs, BuildConfig{GOOS: parts[0], GOARCH: parts[1]})
+	}
+
+	return configs, nil
+}
+
+// env returns the environment of the go command invoked to load packages for this
+// configuration, or nil to inherit the environment of the indexer.
+func (c BuildConfig) env() []string {
+	if c == (BuildConfig{}) {
+		return nil
+	}
+
+	return append(os.Environ(), "GOOS="+c.GOOS, "GOARCH="+c.GOARCH)
+}
+
+// buildConfigs returns the configurations for which the project packages are loaded.
+func (i *Indexer) buildConfigs() []BuildConfig {
+	if len(i.generationOptions.BuildConfigs) == 0 {
+		return []BuildConfig{{}}
+	}
+
+	return i.generationOptions.BuildConfigs
+}
+
+// buildFlags returns the flags passed to the go command to load packages.
+func (i *Indexer) buildFlags() []string {
+	if len(i.generationOptions.BuildTags) == 0 {
+		return nil
+	}
+
+	return []string{"-tags=" + strings.Join(i.generationOptions.BuildTags, ",")}
+}
+
+// linkBuildConfigDefinitions makes the definitions of files that are shared by the packages
+// of several build configurations retrievable by the objects of every configuration. Such
+// files are indexed only through the package of the first configuration that includes them
+// (see positionAndDocument), so without this step a reference from a file specific to a later
+// configuration would not resolve to the definition in the shared file.
+func (i *Indexer) linkBuildConfigDefinitions() {
+	if len(i.generationOptions.BuildConfigs) < 2 {
+		return
+	}
+
+	type definition struct {
+		ident *ast.Ident
+		obj   types.Object
+	}
+
+	// filename -> offset -> definition, for the package that indexed the file
+	canonicalDefinitions := map[string]map[int]definition{}
+	for _, p := range i.packages {
+		for _, ident := range identsOf(p.TypesInfo.Defs, i.generationOptions.Deterministic) {
+			obj := p.TypesInfo.Defs[ident]
+			if obj == nil {
+				continue
+			}
+
+			position := p.Fset.Position(ident.Pos())
+			if packages := i.packagesByFile[position.Filename]; len(packages) == 0 || packages[0] != p {
+				continue
+			}
+
+			if canonicalDefinitions[position.Filename] == nil {
+				canonicalDefinitions[position.Filename] = map[int]definition{}
+			}
+			canonicalDefinitions[position.Filename][position.Offset] = definition{ident, obj}
+		}
+	}
+
+	// Definition infos are read and written in the same pass, so packages are visited serially
+	for _, p := range i.indexedPackages() {
+		for _, ident := range identsOf(p.TypesInfo.Defs, i.generationOptions.Deterministic) {
+			obj := p.TypesInfo.Defs[ident]
+			if obj == nil {
+				continue
+			}
+
+			position := p.Fset.Position(ident.Pos())
+			canonical, ok := canonicalDefinitions[position.Filename][position.Offset]
+			if !ok || canonical.obj == obj {
+				continue
+			}
+
+			if d := i.getDefinitionInfo(canonical.obj, canonical.ident); d != nil {
+				i.setDefinitionInfo(obj, ident, d)
+			}
+		}
+	}
+}
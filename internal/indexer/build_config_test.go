@@ -0,0 +1,108 @@
+package indexer
+
+import (
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestParseBuildConfigs(t *testing.T) {
+	configs, err := ParseBuildConfigs("linux/amd64, windows/amd64,darwin/arm64,")
+	if err != nil {
+		t.Fatalf("unexpected error parsing build configurations: %s", err)
+	}
+
+	expected := []BuildConfig{
+		{GOOS: "linux", GOARCH: "amd64"},
+		{GOOS: "windows", GOARCH: "amd64"},
+		{GOOS: "darwin", GOARCH: "arm64"},
+	}
+	if diff := cmp.Diff(expected, configs); diff != "" {
+		t.Errorf("unexpected build configurations (-want +got): %s", diff)
+	}
+
+	if configs, err := ParseBuildConfigs(""); err != nil || len(configs) != 0 {
+		t.Errorf("unexpected build configurations for empty input: %v (%v)", configs, err)
+	}
+
+	for _, input := range []string{"linux", "linux/", "/amd64", "linux/amd64/v2"} {
+		if _, err := ParseBuildConfigs(input); err == nil {
+			t.Errorf("expected error parsing %q", input)
+		}
+	}
+}
+
+func TestBuildConfigEnv(t *testing.T) {
+	if env := (BuildConfig{}).env(); env != nil {
+		t.Errorf("expected host configuration to inherit the environment")
+	}
+
+	env := BuildConfig{GOOS: "windows", GOARCH: "386"}.env()
+	if diff := cmp.Diff([]string{"GOOS=windows", "GOARCH=386"}, env[len(env)-2:]); diff != "" {
+		t.Errorf("unexpected environment (-want +got): %s", diff)
+	}
+}
+
+func TestBuildFlags(t *testing.T) {
+	indexer := &Indexer{}
+	if flags := indexer.buildFlags(); flags != nil {
+		t.Errorf("unexpected build flags: %v", flags)
+	}
+
+	indexer.generationOptions.BuildTags = []string{"integration", "linux"}
+	if diff := cmp.Diff([]string{"-tags=integration,linux"}, indexer.buildFlags()); diff != "" {
+		t.Errorf("unexpected build flags (-want +got): %s", diff)
+	}
+}
+
+func TestIndexer_buildConfigs(t *testing.T) {
+	w := &capturingWriter{
+		ranges:    map[uint64]protocol.Range{},
+		documents: map[uint64]protocol.Document{},
+		contains:  map[uint64]uint64{},
+	}
+
+	generationOptions := NewGenerationOptions()
+	generationOptions.Deterministic = true
+	generationOptions.BuildConfigs = []BuildConfig{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "amd64"}}
+	indexTestdata(t, "build_configs", w, generationOptions)
+
+	projectRoot := path.Join(getRepositoryRoot(t), "build_configs")
+	shared := "file://" + path.Join(projectRoot, "shared.go")
+
+	documents := map[string]int{}
+	for _, document := range w.documents {
+		documents[document.URI]++
+	}
+	expectedDocuments := map[string]int{
+		shared: 1,
+		"file://" + path.Join(projectRoot, "open_linux.go"):   1,
+		"file://" + path.Join(projectRoot, "open_windows.go"): 1,
+	}
+	if diff := cmp.Diff(expectedDocuments, documents); diff != "" {
+		t.Errorf("unexpected documents (-want +got): %s", diff)
+	}
+
+	// The shared definition is linked to its result set by a next edge
+	resultSets := map[uint64]uint64{}
+	for _, elem := range w.elements {
+		if e, ok := elem.(protocol.Next); ok {
+			resultSets[e.OutV] = e.InV
+		}
+	}
+	definition := mustRange(t, w, shared, 6, 5)
+
+	for _, filename := range []string{"open_linux.go", "open_windows.go"} {
+		t.Run(filename, func(t *testing.T) {
+			r := mustRange(t, w, "file://"+path.Join(projectRoot, filename), 5, 28)
+
+			assertRanges(t, w, findDefinitionRangesByRangeOrResultSetID(w, r.ID), []string{"shared.go:6:5-6:11"}, "Shared definition")
+
+			if resultSets[r.ID] != resultSets[definition.ID] {
+				t.Errorf("unexpected result set of reference. want=%d have=%d", resultSets[definition.ID], resultSets[r.ID])
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"golang.org/x/tools/go/packages"
+)
+
+// callHierarchy holds the data shared by the call hierarchy passes.
+type callHierarchy struct {
+	implementations map[string][]callTarget // interface method moniker identifier -> implementing methods
+}
+
+// callTarget is a function that may be called, identified by its definition if it is defined
+// in the project and by its moniker otherwise.
+type callTarget struct {
+	definition        *DefinitionInfo
+	monikerPackage    string
+	monikerIdentifier string
+}
+
+// calls are the call sites of a callee within a caller.
+type calls struct {
+	rangeIDs []uint64
+	dynamic  bool
+}
+
+// recordMethodImplementations records the methods implementing each method of the given interface,
+// so that calls of the interface method can be linked to the implementations by indexCallHierarchy.
+func (i *Indexer) recordMethodImplementations(from implDef, tos []implDef) {
+	if i.callHierarchy == nil {
+		i.callHierarchy = newCallHierarchy()
+	}
+
+	for _, fromName := range sortedKeys(from.methodsByName) {
+		fromMethod := from.methodsByName[fromName]
+
+		for _, to := range tos {
+			// Skip aliases because their methods are redundant with
+			// the underlying concrete type's methods.
+			if to.typeNameIsAlias {
+				continue
+			}
+
+			toMethod, ok := to.methodsByName[fromName]
+			if !ok {
+				continue
+			}
+
+			i.callHierarchy.implementations[fromMethod.monikerIdentifier] = append(i.callHierarchy.implementations[fromMethod.monikerIdentifier], callTarget{
+				definition:        toMethod.definition,
+				monikerPackage:    toMethod.monikerPackage,
+				monikerIdentifier: toMethod.monikerIdentifier,
+			})
+		}
+	}
+}
+
+func newCallHierarchy() *callHierarchy {
+	return &callHierarchy{
+		implementations: map[string][]callTarget{},
+	}
+}
+
+// indexCallHierarchy emits a call edge from the result set of each function and method of the
+// project to the result set of each function that it calls. Calls within function literals are
+// attributed to the enclosing function. Functions defined in a dependency are represented by a
+// result set linked to their import moniker. Calls of interface methods are also linked to each
+// implementation of the method found by indexImplementations as dynamic calls. This includes the
+// functions copied from a previous dump, whose call edges are not copied.
+func (i *Indexer) indexCallHierarchy() {
+	if !i.generationOptions.EnableCallHierarchy {
+		return
+	}
+
+	if i.callHierarchy == nil {
+		i.callHierarchy = newCallHierarchy()
+	}
+
+	i.visitEachLoadedPackage("Indexing call hierarchy", i.indexCallHierarchyForPackage)
+}
+
+// indexCallHierarchyForPackage emits the call edges of the functions declared in the given package.
+func (i *Indexer) indexCallHierarchyForPackage(p *packages.Package) {
+	for _, f := range p.Syntax {
+		for _, decl := range f.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+
+			i.indexCallsOfFunction(p, funcDecl)
+		}
+	}
+}
+
+// indexCallsOfFunction emits the call edges of the given function declaration.
+func (i *Indexer) indexCallsOfFunction(p *packages.Package, funcDecl *ast.FuncDecl) {
+	if _, document, ok := i.positionAndDocument(p, funcDecl.Name.Pos()); !ok || document == nil {
+		return
+	}
+
+	caller := i.getDefinitionInfo(p.TypesInfo.Defs[funcDecl.Name], funcDecl.Name)
+	if caller == nil {
+		return
+	}
+
+	callsByCallee := map[uint64]*calls{}
+	addCall := func(calleeResultSetID, rangeID uint64, dynamic bool) {
+		c, ok := callsByCallee[calleeResultSetID]
+		if !ok {
+			c = &calls{dynamic: dynamic}
+			callsByCallee[calleeResultSetID] = c
+		}
+
+		if n := len(c.rangeIDs); n > 0 && c.rangeIDs[n-1] == rangeID {
+			// Several implementations of an interface method may resolve to the same method
+			// (e.g., a method promoted from an embedded type)
+			return
+		}
+
+		c.rangeIDs = append(c.rangeIDs, rangeID)
+		c.dynamic = c.dynamic && dynamic
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		ident := calleeIdent(call)
+		if ident == nil {
+			return true
+		}
+		callee, ok := p.TypesInfo.Uses[ident].(*types.Func)
+		if !ok {
+			return true
+		}
+		// Methods of instantiated generic types resolve to the method of the generic type, which
+		// is the method that was indexed
+		callee = callee.Origin()
+
+		// Call sites are the ranges of the called identifiers, which are emitted by indexReferences
+		// or copied from the previous dump
+		rangeID, ok := i.rangeAt(p.Fset.Position(ident.Pos()))
+		if !ok {
+			return true
+		}
+
+		if resultSetID, ok := i.calleeResultSet(p, callee); ok {
+			addCall(resultSetID, rangeID, false)
+		}
+
+		if isInterfaceMethod(callee) {
+			monikerIdentifier := joinMonikerParts(makeMonikerPackage(callee), makeMonikerIdentifier(i.packageDataCache, p, callee))
+
+			for _, target := range i.callHierarchy.implementations[monikerIdentifier] {
+				if resultSetID, ok := i.callTargetResultSet(target); ok {
+					addCall(resultSetID, rangeID, true)
+				}
+			}
+		}
+
+		return true
+	})
+
+	for _, calleeResultSetID := range sortedKeys(callsByCallee) {
+		c := callsByCallee[calleeResultSetID]
+		_ = i.emitter.EmitCallHierarchyCall(caller.ResultSetID, calleeResultSetID, c.rangeIDs, caller.DocumentID, c.dynamic)
+	}
+}
+
+// calleeResultSet returns the result set of the given function.
+func (i *Indexer) calleeResultSet(p *packages.Package, callee *types.Func) (uint64, bool) {
+	if d := i.getDefinitionInfo(callee, nil); d != nil {
+		return d.ResultSetID, true
+	}
+
+	monikerID, ok := i.ensureImportMonikerFor(p, callee)
+	if !ok {
+		return 0, false
+	}
+
+	return i.ensureMonikerResultSet(monikerID), true
+}
+
+// callTargetResultSet returns the result set of the given implementation of an interface method.
+func (i *Indexer) callTargetResultSet(target callTarget) (uint64, bool) {
+	if target.definition != nil {
+		return target.definition.ResultSetID, true
+	}
+
+	monikerID, ok := i.ensureImportMonikerForIdentifier(target.monikerPackage, target.monikerIdentifier, lsif.SymbolKindMethod)
+	if !ok {
+		return 0, false
+	}
+
+	return i.ensureMonikerResultSet(monikerID), true
+}
+
+// rangeAt returns the identifier of the range emitted at the given position.
+func (i *Indexer) rangeAt(pos token.Position) (uint64, bool) {
+	i.stripedMutex.RLockKey(pos.Filename)
+	defer i.stripedMutex.RUnlockKey(pos.Filename)
+
+	rangeID, ok := i.ranges[pos.Filename][pos.Offset]
+	return rangeID, ok
+}
+
+// calleeIdent returns the identifier naming the function called by the given call expression,
+// or nil if the called function is not named (e.g. a function literal or the result of a call).
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	fun := unparen(call.Fun)
+
+	// Explicit instantiations of generic functions, as in f[int](x)
+	switch v := fun.(type) {
+	case *ast.IndexExpr:
+		fun = unparen(v.X)
+	case *ast.IndexListExpr:
+		fun = unparen(v.X)
+	}
+
+	switch v := fun.(type) {
+	case *ast.Ident:
+		return v
+	case *ast.SelectorExpr:
+		return v.Sel
+	}
+
+	return nil
+}
+
+// isInterfaceMethod returns true if the given function is a method of an interface.
+func isInterfaceMethod(fn *types.Func) bool {
+	signature, ok := fn.Type().(*types.Signature)
+	if !ok || signature.Recv() == nil {
+		return false
+	}
+
+	return types.IsInterface(signature.Recv().Type())
+}
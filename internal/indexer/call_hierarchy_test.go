@@ -0,0 +1,74 @@
+package indexer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path/filepath"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestIndexCallHierarchy(t *testing.T) {
+	w := &capturingWriter{
+		ranges:    map[uint64]protocol.Range{},
+		documents: map[uint64]protocol.Document{},
+		contains:  map[uint64]uint64{},
+	}
+
+	generationOptions := NewGenerationOptions()
+	generationOptions.EnableCallHierarchy = true
+	indexFixtures(t, w, generationOptions)
+
+	location := func(filename string, line, character int) string {
+		uri := "file://" + filepath.Join(getRepositoryRoot(t), "fixtures", filename)
+		return stringifyFileRange(uri, mustRange(t, w, uri, line, character))
+	}
+
+	calls := map[string]int{}
+	for _, call := range renderCalls(w.elements) {
+		calls[call]++
+	}
+
+	for name, expected := range map[string]string{
+		"method":                   fmt.Sprintf("%s -> %s (dynamic=false) at %s", location("hierarchies.go", 20, 16), location("hierarchies.go", 22, 14), location("hierarchies.go", 20, 43)),
+		"interface method":         fmt.Sprintf("%s -> %s (dynamic=false) at %s", location("hierarchies.go", 31, 5), location("hierarchies.go", 6, 1), location("hierarchies.go", 32, 10)),
+		"implementation":           fmt.Sprintf("%s -> %s (dynamic=true) at %s", location("hierarchies.go", 31, 5), location("hierarchies.go", 20, 16), location("hierarchies.go", 32, 10)),
+		"method of a generic type": fmt.Sprintf("%s -> %s (dynamic=false) at %s", location("hierarchies.go", 47, 5), location("hierarchies.go", 44, 19), location("hierarchies.go", 50, 4)),
+		"function of a dependency": fmt.Sprintf("%s -> github.com/golang/go/std/fmt:Println (dynamic=false) at %s", location("named_import.go", 7, 5), location("named_import.go", 8, 1)),
+	} {
+		if count := calls[expected]; count != 1 {
+			t.Errorf("unexpected number of calls of %s. want=%d have=%d: %s", name, 1, count, expected)
+		}
+	}
+}
+
+func TestCalleeIdent(t *testing.T) {
+	testCases := map[string]string{
+		"f(x)":          "f",
+		"pkg.F(x)":      "F",
+		"(pkg.F)(x)":    "F",
+		"f[int](x)":     "f",
+		"m[K, V](x)":    "m",
+		"x.y.Method()":  "Method",
+		"func() {}()":   "",
+		"f(x)(y)":       "",
+		"fns[0].Call()": "Call",
+	}
+
+	for source, expected := range testCases {
+		expr, err := parser.ParseExpr(source)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", source, err)
+		}
+
+		name := ""
+		if ident := calleeIdent(expr.(*ast.CallExpr)); ident != nil {
+			name = ident.Name
+		}
+		if name != expected {
+			t.Errorf("unexpected callee of %q. want=%q have=%q", source, expected, name)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/packages"
+)
+
+// PackageErrors are the errors reported while loading and type checking a project package.
+type PackageErrors struct {
+	PkgPath string
+	Errors  []packages.Error
+}
+
+// diagnosticSources maps the kind of a package error to the source of its diagnostic.
+var diagnosticSources = map[packages.ErrorKind]string{
+	packages.UnknownError: "go",
+	packages.ListError:    "go list",
+	packages.ParseError:   "go/parser",
+	packages.TypeError:    "go/types",
+}
+
+// emitDiagnostics emits a diagnostic result for each document in which an error was reported
+// while loading or type checking the project packages, or in which an analyzer reported a
+// finding. Errors without a position, and errors in files that are not documents of the
+// project, are only reported by PackageErrors. Findings reported identically for several
+// build configurations are emitted once.
+func (i *Indexer) emitDiagnostics() {
+	diagnosticsByFile := map[string][]lsif.Diagnostic{}
+	lines := lineCache{}
+
+	for _, packageErrors := range i.PackageErrors() {
+		for _, err := range packageErrors.Errors {
+			filename, line, column, ok := parseErrorPosition(err.Pos)
+			if !ok {
+				continue
+			}
+			if _, ok := i.documents[filename]; !ok {
+				continue
+			}
+
+			diagnosticsByFile[filename] = append(diagnosticsByFile[filename], lsif.Diagnostic{
+				Range:    lines.diagnosticRange(filename, line, column),
+				Severity: lsif.SeverityError,
+				Source:   diagnosticSources[err.Kind],
+				Message:  err.Msg,
+			})
+		}
+	}
+
+	seen := map[analysisFinding]struct{}{}
+	for _, finding := range i.analysisFindings {
+		filename := finding.Start.Filename
+		if _, ok := i.documents[filename]; !ok {
+			continue
+		}
+		if _, ok := seen[finding]; ok {
+			continue
+		}
+		seen[finding] = struct{}{}
+
+		diagnosticRange := lines.diagnosticRange(filename, finding.Start.Line, finding.Start.Column)
+		if finding.End.IsValid() {
+			diagnosticRange.End = protocol.Pos{Line: finding.End.Line - 1, Character: finding.End.Column - 1}
+		}
+
+		diagnosticsByFile[filename] = append(diagnosticsByFile[filename], lsif.Diagnostic{
+			Range:    diagnosticRange,
+			Severity: lsif.SeverityWarning,
+			Code:     finding.Category,
+			Source:   finding.Analyzer,
+			Message:  finding.Message,
+		})
+	}
+
+	for _, filename := range sortedKeys(diagnosticsByFile) {
+		diagnostics := diagnosticsByFile[filename]
+		sort.SliceStable(diagnostics, func(i, j int) bool {
+			if diagnostics[i].Range.Start.Line != diagnostics[j].Range.Start.Line {
+				return diagnostics[i].Range.Start.Line < diagnostics[j].Range.Start.Line
+			}
+
+			return diagnostics[i].Range.Start.Character < diagnostics[j].Range.Start.Character
+		})
+
+		resultID := i.emitter.EmitDiagnosticResult(diagnostics)
+		_ = i.emitter.EmitTextDocumentDiagnostic(i.documents[filename].DocumentID, resultID)
+	}
+}
+
+// PackageErrors returns the distinct errors reported while loading and type checking each
+// project package, ordered by package path. Packages without errors are omitted. The test
+// variants of a package are reported together with the package.
+func (i *Indexer) PackageErrors() []PackageErrors {
+	errorsByPackage := map[string][]packages.Error{}
+	seen := map[string]map[string]struct{}{}
+
+	for _, p := range i.packages {
+		for _, err := range p.Errors {
+			if seen[p.PkgPath] == nil {
+				seen[p.PkgPath] = map[string]struct{}{}
+			}
+			if _, ok := seen[p.PkgPath][err.Error()]; ok {
+				continue
+			}
+			seen[p.PkgPath][err.Error()] = struct{}{}
+
+			errorsByPackage[p.PkgPath] = append(errorsByPackage[p.PkgPath], err)
+		}
+	}
+
+	packageErrors := make([]PackageErrors, 0, len(errorsByPackage))
+	for _, pkgPath := range sortedKeys(errorsByPackage) {
+		packageErrors = append(packageErrors, PackageErrors{PkgPath: pkgPath, Errors: errorsByPackage[pkgPath]})
+	}
+
+	return packageErrors
+}
+
+// parseErrorPosition parses the position of a package error, which has the form file:line:column
+// or file:line. The returned line and column are 1-indexed, and the column is zero if absent.
+func parseErrorPosition(pos string) (filename string, line, column int, ok bool) {
+	parts := make([]int, 0, 2)
+	for len(parts) < 2 {
+		index := strings.LastIndex(pos, ":")
+		if index < 0 {
+			break
+		}
+
+		n, err := strconv.Atoi(pos[index+1:])
+		if err != nil {
+			break
+		}
+
+		parts = append([]int{n}, parts...)
+		pos = pos[:index]
+	}
+
+	switch len(parts) {
+	case 1:
+		return pos, parts[0], 0, pos != ""
+	case 2:
+		return pos, parts[0], parts[1], pos != ""
+	}
+
+	return "", 0, 0, false
+}
+
+// lineCache holds the lines of the files in which diagnostics are reported.
+type lineCache map[string][][]byte
+
+// diagnosticRange returns the range of a diagnostic reported at the given 1-indexed position.
+// The range spans the identifier starting at the position, or a single character if there
+// is no such identifier. Diagnostics without a column span their entire line.
+func (c lineCache) diagnosticRange(filename string, line, column int) lsif.Range {
+	lines, ok := c[filename]
+	if !ok {
+		contents, _ := os.ReadFile(filename)
+		lines = bytes.Split(contents, []byte("\n"))
+		c[filename] = lines
+	}
+
+	var text []byte
+	if line >= 1 && line <= len(lines) {
+		text = bytes.TrimSuffix(lines[line-1], []byte("\r"))
+	}
+
+	start := protocol.Pos{Line: line - 1, Character: column - 1}
+	end := protocol.Pos{Line: line - 1, Character: column - 1}
+	if column == 0 {
+		start.Character = 0
+		end.Character = len(text)
+		return lsif.Range{Start: start, End: end}
+	}
+
+	for end.Character < len(text) && isIdentifierByte(text[end.Character]) {
+		end.Character++
+	}
+	if end.Character == start.Character && end.Character < len(text) {
+		end.Character++
+	}
+
+	return lsif.Range{Start: start, End: end}
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
@@ -0,0 +1,96 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestEmitDiagnostics(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filename, []byte("package main\n\nfunc main() {\n\tfoo()\n\tx := )\n}\n"), 0644); err != nil {
+		t.Fatalf("unexpected error writing file: %s", err)
+	}
+
+	w := &capturingWriter{}
+	indexer := &Indexer{
+		emitter:   NewJSONEmitter(w),
+		documents: map[string]*DocumentInfo{filename: {DocumentID: 42}},
+		packages: []*packages.Package{
+			{
+				ID:      "example.com/main",
+				PkgPath: "example.com/main",
+				Errors: []packages.Error{
+					{Pos: filename + ":5:7", Msg: "expected operand, found ')'", Kind: packages.ParseError},
+					{Pos: filename + ":4:2", Msg: "undefined: foo", Kind: packages.TypeError},
+				},
+			},
+			{
+				// The test variant reports the same errors
+				ID:      "example.com/main [example.com/main.test]",
+				PkgPath: "example.com/main",
+				Errors: []packages.Error{
+					{Pos: filename + ":4:2", Msg: "undefined: foo", Kind: packages.TypeError},
+					{Pos: "/elsewhere/dep.go:1:1", Msg: "could not import example.com/dep", Kind: packages.TypeError},
+					{Pos: "-", Msg: "no required module provides package example.com/dep", Kind: packages.ListError},
+				},
+			},
+		},
+	}
+
+	indexer.emitDiagnostics()
+
+	expected := []interface{}{
+		lsif.NewDiagnosticResult(1, []lsif.Diagnostic{
+			{
+				Range:    lsif.Range{Start: protocol.Pos{Line: 3, Character: 1}, End: protocol.Pos{Line: 3, Character: 4}},
+				Severity: lsif.SeverityError,
+				Source:   "go/types",
+				Message:  "undefined: foo",
+			},
+			{
+				Range:    lsif.Range{Start: protocol.Pos{Line: 4, Character: 6}, End: protocol.Pos{Line: 4, Character: 7}},
+				Severity: lsif.SeverityError,
+				Source:   "go/parser",
+				Message:  "expected operand, found ')'",
+			},
+		}),
+		lsif.NewTextDocumentDiagnostic(2, 42, 1),
+	}
+	if diff := cmp.Diff(expected, w.elements); diff != "" {
+		t.Errorf("unexpected elements (-want +got): %s", diff)
+	}
+
+	packageErrors := indexer.PackageErrors()
+	if len(packageErrors) != 1 || packageErrors[0].PkgPath != "example.com/main" || len(packageErrors[0].Errors) != 4 {
+		t.Errorf("unexpected package errors: %v", packageErrors)
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	testCases := []struct {
+		pos      string
+		filename string
+		line     int
+		column   int
+		ok       bool
+	}{
+		{pos: "/dev/repo/main.go:12:5", filename: "/dev/repo/main.go", line: 12, column: 5, ok: true},
+		{pos: "/dev/repo/main.go:12", filename: "/dev/repo/main.go", line: 12, column: 0, ok: true},
+		{pos: `C:\repo\main.go:12:5`, filename: `C:\repo\main.go`, line: 12, column: 5, ok: true},
+		{pos: "-", ok: false},
+		{pos: "", ok: false},
+	}
+
+	for _, testCase := range testCases {
+		filename, line, column, ok := parseErrorPosition(testCase.pos)
+		if filename != testCase.filename || line != testCase.line || column != testCase.column || ok != testCase.ok {
+			t.Errorf("unexpected position for %q: have=(%q, %d, %d, %v)", testCase.pos, filename, line, column, ok)
+		}
+	}
+}
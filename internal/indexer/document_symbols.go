@@ -0,0 +1,185 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/packages"
+)
+
+// emitDocumentSymbols emits a document symbol result for each document that declares at
+// least one symbol. The symbols of a document are its top-level functions, methods, types,
+// variables, and constants in source order. Struct fields and interface methods are nested
+// below their type.
+func (i *Indexer) emitDocumentSymbols() {
+	i.visitEachDocumentFile("Emitting document symbols", i.emitDocumentSymbolsForFile)
+}
+
+// emitDocumentSymbolsForFile emits the document symbol result of the given file.
+func (i *Indexer) emitDocumentSymbolsForFile(p *packages.Package, f *ast.File, d *DocumentInfo) {
+	symbols := documentSymbols(p.Fset, f)
+	if len(symbols) == 0 {
+		return
+	}
+
+	resultID := i.emitter.EmitDocumentSymbolResult(symbols)
+	_ = i.emitter.EmitTextDocumentDocumentSymbol(d.DocumentID, resultID)
+}
+
+// documentSymbols returns the symbols declared at the top level of the given file.
+func documentSymbols(fset *token.FileSet, f *ast.File) []lsif.DocumentSymbol {
+	var symbols []lsif.DocumentSymbol
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			symbols = append(symbols, funcDeclSymbol(fset, decl))
+
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// The range of a declaration without parentheses includes its keyword
+				var node ast.Node = spec
+				if !decl.Lparen.IsValid() {
+					node = decl
+				}
+
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					symbols = append(symbols, typeSpecSymbol(fset, spec, node))
+
+				case *ast.ValueSpec:
+					kind := lsif.SymbolKindVariable
+					if decl.Tok == token.CONST {
+						kind = lsif.SymbolKindConstant
+					}
+
+					for _, name := range spec.Names {
+						if name.Name != "_" {
+							symbols = append(symbols, newDocumentSymbol(fset, name.Name, kind, node, name))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return symbols
+}
+
+// funcDeclSymbol returns the symbol of the given function or method. Methods are named after
+// their receiver type as in (*T).Method.
+func funcDeclSymbol(fset *token.FileSet, decl *ast.FuncDecl) lsif.DocumentSymbol {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return newDocumentSymbol(fset, decl.Name.Name, lsif.SymbolKindFunction, decl, decl.Name)
+	}
+
+	name := "(" + types.ExprString(decl.Recv.List[0].Type) + ")." + decl.Name.Name
+	return newDocumentSymbol(fset, name, lsif.SymbolKindMethod, decl, decl.Name)
+}
+
+// typeSpecSymbol returns the symbol of the given type, whose declaration spans the given node.
+func typeSpecSymbol(fset *token.FileSet, spec *ast.TypeSpec, node ast.Node) lsif.DocumentSymbol {
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		symbol := newDocumentSymbol(fset, spec.Name.Name, lsif.SymbolKindStruct, node, spec.Name)
+		symbol.Children = fieldSymbols(fset, t)
+		return symbol
+
+	case *ast.InterfaceType:
+		symbol := newDocumentSymbol(fset, spec.Name.Name, lsif.SymbolKindInterface, node, spec.Name)
+		symbol.Children = interfaceMethodSymbols(fset, t)
+		return symbol
+	}
+
+	return newDocumentSymbol(fset, spec.Name.Name, lsif.SymbolKindClass, node, spec.Name)
+}
+
+// fieldSymbols returns the symbols of the fields of the given struct. Embedded fields are named
+// after their type, and the fields of anonymous struct types are nested below their field.
+func fieldSymbols(fset *token.FileSet, t *ast.StructType) []lsif.DocumentSymbol {
+	var symbols []lsif.DocumentSymbol
+	for _, field := range t.Fields.List {
+		var children []lsif.DocumentSymbol
+		if structType, ok := unparen(unstar(field.Type)).(*ast.StructType); ok {
+			children = fieldSymbols(fset, structType)
+		}
+
+		if len(field.Names) == 0 {
+			typ := unstar(field.Type)
+			symbol := newDocumentSymbol(fset, types.ExprString(typ), lsif.SymbolKindField, field, typ)
+			symbol.Children = children
+			symbols = append(symbols, symbol)
+			continue
+		}
+
+		for _, name := range field.Names {
+			symbol := newDocumentSymbol(fset, name.Name, lsif.SymbolKindField, field, name)
+			symbol.Children = children
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+// interfaceMethodSymbols returns the symbols of the methods and embedded types of the given
+// interface. Type constraint unions are omitted.
+func interfaceMethodSymbols(fset *token.FileSet, t *ast.InterfaceType) []lsif.DocumentSymbol {
+	var symbols []lsif.DocumentSymbol
+	for _, field := range t.Methods.List {
+		if len(field.Names) == 0 {
+			switch typ := field.Type.(type) {
+			case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+				symbols = append(symbols, newDocumentSymbol(fset, types.ExprString(typ), lsif.SymbolKindInterface, field, typ))
+			}
+
+			continue
+		}
+
+		for _, name := range field.Names {
+			symbols = append(symbols, newDocumentSymbol(fset, name.Name, lsif.SymbolKindMethod, field, name))
+		}
+	}
+
+	return symbols
+}
+
+// newDocumentSymbol creates a symbol whose declaration spans the given node and whose name
+// spans the given selection node.
+func newDocumentSymbol(fset *token.FileSet, name string, kind lsif.SymbolKind, node, selection ast.Node) lsif.DocumentSymbol {
+	return lsif.DocumentSymbol{
+		Name:           name,
+		Kind:           kind,
+		Range:          nodeRange(fset, node),
+		SelectionRange: nodeRange(fset, selection),
+	}
+}
+
+// nodeRange returns the LSP range (0-indexed) of the given node.
+func nodeRange(fset *token.FileSet, node ast.Node) lsif.Range {
+	start := fset.Position(node.Pos())
+	end := fset.Position(node.End())
+
+	return lsif.Range{
+		Start: protocol.Pos{Line: start.Line - 1, Character: start.Column - 1},
+		End:   protocol.Pos{Line: end.Line - 1, Character: end.Column - 1},
+	}
+}
+
+func unstar(expr ast.Expr) ast.Expr {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return star.X
+	}
+
+	return expr
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		return unparen(paren.X)
+	}
+
+	return expr
+}
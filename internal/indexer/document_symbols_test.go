@@ -0,0 +1,103 @@
+package indexer
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+const documentSymbolsSource = `package main
+
+const Answer = 42
+
+var (
+	x, _ = 1, 2
+	y    int
+)
+
+type Point struct {
+	*Base
+	X, Y  int
+	inner struct{ Z int }
+}
+
+type Shape interface {
+	fmt.Stringer
+	Area() float64
+	~int | ~float64
+}
+
+type ID string
+
+func (p *Point) Norm() int { return 0 }
+
+func main() {}
+`
+
+func TestDocumentSymbols(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", documentSymbolsSource, 0)
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err)
+	}
+
+	type symbol struct {
+		Name     string
+		Kind     lsif.SymbolKind
+		Children []symbol
+	}
+	var simplify func(symbols []lsif.DocumentSymbol) []symbol
+	simplify = func(symbols []lsif.DocumentSymbol) (simplified []symbol) {
+		for _, s := range symbols {
+			simplified = append(simplified, symbol{Name: s.Name, Kind: s.Kind, Children: simplify(s.Children)})
+		}
+		return simplified
+	}
+
+	symbols := documentSymbols(fset, f)
+
+	expected := []symbol{
+		{Name: "Answer", Kind: lsif.SymbolKindConstant},
+		{Name: "x", Kind: lsif.SymbolKindVariable},
+		{Name: "y", Kind: lsif.SymbolKindVariable},
+		{Name: "Point", Kind: lsif.SymbolKindStruct, Children: []symbol{
+			{Name: "Base", Kind: lsif.SymbolKindField},
+			{Name: "X", Kind: lsif.SymbolKindField},
+			{Name: "Y", Kind: lsif.SymbolKindField},
+			{Name: "inner", Kind: lsif.SymbolKindField, Children: []symbol{
+				{Name: "Z", Kind: lsif.SymbolKindField},
+			}},
+		}},
+		{Name: "Shape", Kind: lsif.SymbolKindInterface, Children: []symbol{
+			{Name: "fmt.Stringer", Kind: lsif.SymbolKindInterface},
+			{Name: "Area", Kind: lsif.SymbolKindMethod},
+		}},
+		{Name: "ID", Kind: lsif.SymbolKindClass},
+		{Name: "(*Point).Norm", Kind: lsif.SymbolKindMethod},
+		{Name: "main", Kind: lsif.SymbolKindFunction},
+	}
+	if diff := cmp.Diff(expected, simplify(symbols)); diff != "" {
+		t.Errorf("unexpected symbols (-want +got): %s", diff)
+	}
+
+	// const Answer = 42
+	expectedAnswer := lsif.DocumentSymbol{
+		Name:           "Answer",
+		Kind:           lsif.SymbolKindConstant,
+		Range:          lsif.Range{Start: protocol.Pos{Line: 2, Character: 0}, End: protocol.Pos{Line: 2, Character: 17}},
+		SelectionRange: lsif.Range{Start: protocol.Pos{Line: 2, Character: 6}, End: protocol.Pos{Line: 2, Character: 12}},
+	}
+	if diff := cmp.Diff(expectedAnswer, symbols[0]); diff != "" {
+		t.Errorf("unexpected symbol (-want +got): %s", diff)
+	}
+
+	// y    int (within a parenthesized declaration)
+	expectedY := lsif.Range{Start: protocol.Pos{Line: 6, Character: 1}, End: protocol.Pos{Line: 6, Character: 9}}
+	if diff := cmp.Diff(expectedY, symbols[2].Range); diff != "" {
+		t.Errorf("unexpected range (-want +got): %s", diff)
+	}
+}
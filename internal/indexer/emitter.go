@@ -0,0 +1,238 @@
+package indexer
+
+import (
+	"sync/atomic"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+// Emitter is the sink for every vertex and edge produced by the indexer. Each method
+// writes a single element and returns its identifier, which later elements use to refer
+// back to it. Implementations must be safe for concurrent use as the indexer emits from
+// multiple goroutines.
+type Emitter interface {
+	// Graph structure
+	EmitMetaData(root string, info protocol.ToolInfo) uint64
+	EmitProject(languageID string) uint64
+	EmitDocument(languageID, path string) uint64
+	EmitRange(start, end protocol.Pos) uint64
+	EmitResultSet() uint64
+	EmitNext(outV, inV uint64) uint64
+	EmitContains(outV uint64, inVs []uint64) uint64
+
+	// Hovers
+	EmitHoverResult(contents protocol.MarkupContent) uint64
+	EmitTextDocumentHover(outV, inV uint64) uint64
+
+	// Definitions, references, implementations, and type definitions
+	EmitDefinitionResult() uint64
+	EmitTextDocumentDefinition(outV, inV uint64) uint64
+	EmitReferenceResult() uint64
+	EmitTextDocumentReferences(outV, inV uint64) uint64
+	EmitImplementationResult() uint64
+	EmitTextDocumentImplementation(outV, inV uint64) uint64
+	EmitTypeDefinitionResult() uint64
+	EmitTextDocumentTypeDefinition(outV, inV uint64) uint64
+	EmitItem(outV uint64, inVs []uint64, docID uint64) uint64
+	EmitItemOfDefinitions(outV uint64, inVs []uint64, docID uint64) uint64
+	EmitItemOfReferences(outV uint64, inVs []uint64, docID uint64) uint64
+
+	// Monikers
+	EmitMoniker(kind, scheme, identifier string) uint64
+	EmitMonikerEdge(outV, inV uint64) uint64
+	EmitMonikerSymbolKind(monikerID uint64, kind lsif.SymbolKind) uint64
+	EmitPackageInformation(packageName, scheme, version string) uint64
+	EmitPackageInformationEdge(outV, inV uint64) uint64
+
+	// Diagnostics
+	EmitDiagnosticResult(result []lsif.Diagnostic) uint64
+	EmitTextDocumentDiagnostic(outV, inV uint64) uint64
+
+	// Document symbols
+	EmitDocumentSymbolResult(result []lsif.DocumentSymbol) uint64
+	EmitTextDocumentDocumentSymbol(outV, inV uint64) uint64
+
+	// Call hierarchy
+	EmitCallHierarchyCall(outV, inV uint64, fromRanges []uint64, docID uint64, dynamic bool) uint64
+
+	// Type hierarchy
+	EmitTypeHierarchySupertype(outV, inV, rangeID, docID uint64) uint64
+
+	// Folding ranges
+	EmitFoldingRangeResult(result []lsif.FoldingRange) uint64
+	EmitTextDocumentFoldingRange(outV, inV uint64) uint64
+
+	// NumElements returns the number of elements emitted so far.
+	NumElements() uint64
+
+	// Flush is called once the indexer has emitted its last element.
+	Flush() error
+}
+
+// NewJSONEmitter returns the default Emitter, which serializes each element through
+// the given JSON writer. Elements of the LSIF specification are written as the types
+// of the protocol package, and the remaining elements as the types of the lsif package.
+func NewJSONEmitter(jsonWriter writer.JSONWriter) Emitter {
+	return &jsonEmitter{writer: jsonWriter}
+}
+
+type jsonEmitter struct {
+	writer writer.JSONWriter
+	id     uint64
+}
+
+var _ Emitter = &jsonEmitter{}
+
+func (e *jsonEmitter) nextID() uint64 {
+	return atomic.AddUint64(&e.id, 1)
+}
+
+func (e *jsonEmitter) emit(v func(id uint64) interface{}) uint64 {
+	id := e.nextID()
+	e.writer.Write(v(id))
+	return id
+}
+
+func (e *jsonEmitter) EmitMetaData(root string, info protocol.ToolInfo) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewMetaData(id, root, info) })
+}
+
+func (e *jsonEmitter) EmitProject(languageID string) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewProject(id, languageID) })
+}
+
+func (e *jsonEmitter) EmitDocument(languageID, path string) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewDocument(id, languageID, "file://"+path) })
+}
+
+func (e *jsonEmitter) EmitRange(start, end protocol.Pos) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewRange(id, start, end) })
+}
+
+func (e *jsonEmitter) EmitResultSet() uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewResultSet(id) })
+}
+
+func (e *jsonEmitter) EmitNext(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewNext(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitContains(outV uint64, inVs []uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewContains(id, outV, inVs) })
+}
+
+func (e *jsonEmitter) EmitHoverResult(contents protocol.MarkupContent) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewHoverResult(id, contents) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentHover(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewTextDocumentHover(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitDefinitionResult() uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewDefinitionResult(id) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentDefinition(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewTextDocumentDefinition(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitReferenceResult() uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewReferenceResult(id) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentReferences(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewTextDocumentReferences(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitImplementationResult() uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewImplementationResult(id) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentImplementation(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewTextDocumentImplementation(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitTypeDefinitionResult() uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTypeDefinitionResult(id) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentTypeDefinition(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTextDocumentTypeDefinition(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitItem(outV uint64, inVs []uint64, docID uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewItem(id, outV, inVs, docID) })
+}
+
+func (e *jsonEmitter) EmitItemOfDefinitions(outV uint64, inVs []uint64, docID uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewItemOfDefinitions(id, outV, inVs, docID) })
+}
+
+func (e *jsonEmitter) EmitItemOfReferences(outV uint64, inVs []uint64, docID uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewItemOfReferences(id, outV, inVs, docID) })
+}
+
+func (e *jsonEmitter) EmitMoniker(kind, scheme, identifier string) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewMoniker(id, kind, scheme, identifier) })
+}
+
+func (e *jsonEmitter) EmitMonikerEdge(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewMonikerEdge(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitMonikerSymbolKind(monikerID uint64, kind lsif.SymbolKind) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewMonikerSymbolKind(id, monikerID, kind) })
+}
+
+func (e *jsonEmitter) EmitPackageInformation(packageName, scheme, version string) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewPackageInformation(id, packageName, scheme, version) })
+}
+
+func (e *jsonEmitter) EmitPackageInformationEdge(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return protocol.NewPackageInformationEdge(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitDiagnosticResult(result []lsif.Diagnostic) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewDiagnosticResult(id, result) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentDiagnostic(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTextDocumentDiagnostic(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitDocumentSymbolResult(result []lsif.DocumentSymbol) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewDocumentSymbolResult(id, result) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentDocumentSymbol(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTextDocumentDocumentSymbol(id, outV, inV) })
+}
+
+func (e *jsonEmitter) EmitCallHierarchyCall(outV, inV uint64, fromRanges []uint64, docID uint64, dynamic bool) uint64 {
+	return e.emit(func(id uint64) interface{} {
+		return lsif.NewCallHierarchyCall(id, outV, inV, fromRanges, docID, dynamic)
+	})
+}
+
+func (e *jsonEmitter) EmitTypeHierarchySupertype(outV, inV, rangeID, docID uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTypeHierarchySupertype(id, outV, inV, rangeID, docID) })
+}
+
+func (e *jsonEmitter) EmitFoldingRangeResult(result []lsif.FoldingRange) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewFoldingRangeResult(id, result) })
+}
+
+func (e *jsonEmitter) EmitTextDocumentFoldingRange(outV, inV uint64) uint64 {
+	return e.emit(func(id uint64) interface{} { return lsif.NewTextDocumentFoldingRange(id, outV, inV) })
+}
+
+func (e *jsonEmitter) NumElements() uint64 {
+	return atomic.LoadUint64(&e.id)
+}
+
+func (e *jsonEmitter) Flush() error {
+	return e.writer.Flush()
+}
@@ -0,0 +1,100 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"golang.org/x/tools/go/packages"
+)
+
+// emitFoldingRanges emits a folding range result for each document with at least one range
+// spanning several lines.
+func (i *Indexer) emitFoldingRanges() {
+	i.visitEachDocumentFile("Emitting folding ranges", i.emitFoldingRangesForFile)
+}
+
+// emitFoldingRangesForFile emits the folding range result of the given file.
+func (i *Indexer) emitFoldingRangesForFile(p *packages.Package, f *ast.File, d *DocumentInfo) {
+	foldingRanges := foldingRanges(p.Fset, f)
+	if len(foldingRanges) == 0 {
+		return
+	}
+
+	resultID := i.emitter.EmitFoldingRangeResult(foldingRanges)
+	_ = i.emitter.EmitTextDocumentFoldingRange(d.DocumentID, resultID)
+}
+
+// foldingRanges returns the folding ranges of the given file, ordered by their start line.
+// The file folds at blocks (including function bodies), composite literals, struct and
+// interface bodies, parenthesized import, const, var, and type declarations, and comment
+// groups.
+func foldingRanges(fset *token.FileSet, f *ast.File) []lsif.FoldingRange {
+	var foldingRanges []lsif.FoldingRange
+	seen := map[lsif.FoldingRange]struct{}{}
+
+	add := func(foldingRange lsif.FoldingRange) {
+		if foldingRange.EndLine <= foldingRange.StartLine {
+			return
+		}
+		if _, ok := seen[foldingRange]; ok {
+			return
+		}
+
+		seen[foldingRange] = struct{}{}
+		foldingRanges = append(foldingRanges, foldingRange)
+	}
+
+	// addDelimited folds the lines between an opening and a closing delimiter
+	addDelimited := func(open, close token.Pos, kind lsif.FoldingRangeKind) {
+		if open.IsValid() && close.IsValid() {
+			add(lsif.FoldingRange{
+				StartLine: fset.Position(open).Line - 1,
+				EndLine:   fset.Position(close).Line - 2,
+				Kind:      kind,
+			})
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			addDelimited(n.Lbrace, n.Rbrace, "")
+		case *ast.CompositeLit:
+			addDelimited(n.Lbrace, n.Rbrace, "")
+		case *ast.StructType:
+			addDelimited(n.Fields.Opening, n.Fields.Closing, "")
+		case *ast.InterfaceType:
+			addDelimited(n.Methods.Opening, n.Methods.Closing, "")
+
+		case *ast.GenDecl:
+			kind := lsif.FoldingRangeKind("")
+			if n.Tok == token.IMPORT {
+				kind = lsif.FoldingRangeImports
+			}
+
+			addDelimited(n.Lparen, n.Rparen, kind)
+		}
+
+		return true
+	})
+
+	for _, comments := range f.Comments {
+		add(lsif.FoldingRange{
+			StartLine: fset.Position(comments.Pos()).Line - 1,
+			EndLine:   fset.Position(comments.End()).Line - 1,
+			Kind:      lsif.FoldingRangeComment,
+		})
+	}
+
+	sort.SliceStable(foldingRanges, func(i, j int) bool {
+		if foldingRanges[i].StartLine != foldingRanges[j].StartLine {
+			return foldingRanges[i].StartLine < foldingRanges[j].StartLine
+		}
+
+		return foldingRanges[i].EndLine > foldingRanges[j].EndLine
+	})
+
+	return foldingRanges
+}
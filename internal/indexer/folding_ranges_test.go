@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+)
+
+const foldingRangesSource = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Point is a point.
+// It has two coordinates.
+type Point struct {
+	X int
+	Y int
+}
+
+func main() {
+	points := []Point{
+		{X: 1, Y: 2},
+	}
+
+	if len(points) > 0 {
+		fmt.Println(points)
+	}
+	os.Exit(0)
+}
+
+func empty() {
+}
+`
+
+func TestFoldingRanges(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", foldingRangesSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err)
+	}
+
+	expected := []lsif.FoldingRange{
+		{StartLine: 2, EndLine: 4, Kind: lsif.FoldingRangeImports},
+		{StartLine: 7, EndLine: 8, Kind: lsif.FoldingRangeComment},
+		{StartLine: 9, EndLine: 11},
+		{StartLine: 14, EndLine: 22},
+		{StartLine: 15, EndLine: 16},
+		{StartLine: 19, EndLine: 20},
+	}
+	if diff := cmp.Diff(expected, foldingRanges(fset, f)); diff != "" {
+		t.Errorf("unexpected folding ranges (-want +got): %s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/types"
@@ -12,7 +13,10 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/lsif-go/internal/output"
 	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -31,6 +35,117 @@ func getRepositoryRoot(t *testing.T) string {
 	return root
 }
 
+// indexFixtures indexes the testdata fixtures with the given generation options and writes the
+// resulting dump to the given writer.
+func indexFixtures(t *testing.T, w writer.JSONWriter, generationOptions GenerationOptions) *Indexer {
+	return indexTestdata(t, "fixtures", w, generationOptions)
+}
+
+// indexTestdata indexes the module in the given directory of the testdata directory with the given
+// generation options and writes the resulting dump to the given writer.
+func indexTestdata(t *testing.T, dir string, w writer.JSONWriter, generationOptions GenerationOptions) *Indexer {
+	indexer := New(
+		"/dev/github.com/sourcegraph/lsif-go/internal/testdata/"+dir,
+		"github.com/sourcegraph/lsif-go",
+		path.Join(getRepositoryRoot(t), dir),
+		protocol.ToolInfo{Name: "lsif-go", Version: "dev"},
+		"testdata",
+		"0.0.1",
+		dependencies,
+		projectDependencies,
+		NewJSONEmitter(w),
+		NewPackageDataCache(),
+		output.Options{},
+		generationOptions,
+	)
+
+	if err := indexer.Index(); err != nil {
+		t.Fatalf("unexpected error indexing testdata: %s", err.Error())
+	}
+
+	return indexer
+}
+
+// compareDumps fails the test if the given serialized dumps differ, reporting the first line at
+// which they differ.
+func compareDumps(t *testing.T, expected, actual []byte) {
+	if bytes.Equal(expected, actual) {
+		return
+	}
+
+	expectedLines := strings.Split(string(expected), "\n")
+	actualLines := strings.Split(string(actual), "\n")
+
+	for j := 0; j < len(expectedLines) && j < len(actualLines); j++ {
+		if expectedLines[j] != actualLines[j] {
+			t.Fatalf("dumps differ at line %d.\nwant=%s\nhave=%s", j+1, expectedLines[j], actualLines[j])
+		}
+	}
+
+	t.Fatalf("dumps differ in length. want=%d lines have=%d lines", len(expectedLines), len(actualLines))
+}
+
+// renderCalls renders the call hierarchy edges of the given dump independently of the identifiers
+// of its elements. Callers and callees are named by the location of their definition or by their
+// moniker, and call sites by their location.
+func renderCalls(elements []interface{}) []string {
+	uris := map[uint64]string{}           // documentID -> URI
+	rangeDocuments := map[uint64]uint64{} // rangeID -> documentID
+	ranges := map[uint64]protocol.Range{}
+	definitionResults := map[uint64]uint64{} // resultSetID -> definitionResultID
+	items := map[uint64][]uint64{}           // resultID -> rangeIDs
+	monikers := map[uint64]string{}          // monikerID -> identifier
+	monikerEdges := map[uint64]uint64{}      // resultSetID -> monikerID
+
+	for _, element := range elements {
+		switch e := element.(type) {
+		case protocol.Document:
+			uris[e.ID] = e.URI
+		case protocol.Range:
+			ranges[e.ID] = e
+		case protocol.Contains:
+			for _, inV := range e.InVs {
+				rangeDocuments[inV] = e.OutV
+			}
+		case protocol.TextDocumentDefinition:
+			definitionResults[e.OutV] = e.InV
+		case protocol.Item:
+			items[e.OutV] = append(items[e.OutV], e.InVs...)
+		case protocol.Moniker:
+			monikers[e.ID] = e.Identifier
+		case protocol.MonikerEdge:
+			monikerEdges[e.OutV] = e.InV
+		}
+	}
+
+	location := func(rangeID uint64) string {
+		return stringifyFileRange(uris[rangeDocuments[rangeID]], ranges[rangeID])
+	}
+	name := func(resultSetID uint64) string {
+		if rangeIDs := items[definitionResults[resultSetID]]; len(rangeIDs) > 0 {
+			return location(rangeIDs[0])
+		}
+
+		return monikers[monikerEdges[resultSetID]]
+	}
+
+	var calls []string
+	for _, element := range elements {
+		if e, ok := element.(lsif.CallHierarchyCall); ok {
+			var sites []string
+			for _, rangeID := range e.FromRanges {
+				sites = append(sites, location(rangeID))
+			}
+			sort.Strings(sites)
+
+			calls = append(calls, fmt.Sprintf("%s -> %s (dynamic=%v) at %s", name(e.OutV), name(e.InV), e.Dynamic, strings.Join(sites, ", ")))
+		}
+	}
+	sort.Strings(calls)
+
+	return calls
+}
+
 var getTestPackagesOnce sync.Once
 var cachedTestPackages []*packages.Package
 
@@ -436,6 +551,45 @@ func findImplementationRangesByRangeOrResultSetID(w *capturingWriter, id uint64)
 	})
 }
 
+// findResultSetByRangeID returns the result set linked to the range with the given identifier.
+func findResultSetByRangeID(w *capturingWriter, id uint64) (uint64, bool) {
+	for _, elem := range w.elements {
+		if e, ok := elem.(protocol.Next); ok && e.OutV == id {
+			return e.InV, true
+		}
+	}
+
+	return 0, false
+}
+
+// findMonikerResultSet returns the result set linked to the moniker with the given identifier
+// that represents a symbol of a dependency.
+func findMonikerResultSet(w *capturingWriter, identifier string) (uint64, bool) {
+	for _, elem := range w.elements {
+		if e, ok := elem.(protocol.MonikerEdge); ok {
+			if m, ok := findMonikerByID(w, e.InV); ok && m.Identifier == identifier {
+				if _, isRange := w.ranges[e.OutV]; !isRange {
+					return e.OutV, true
+				}
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// typeDefinitionRanges returns the ranges of the type definition result of the result set with
+// the given identifier.
+func typeDefinitionRanges(w *capturingWriter, id uint64) (ranges []protocol.Range) {
+	for _, elem := range w.elements {
+		if e, ok := elem.(lsif.TextDocumentTypeDefinition); ok && e.OutV == id {
+			ranges = append(ranges, findRangesByResultID(w, e.InV)...)
+		}
+	}
+
+	return ranges
+}
+
 // findMonikersByRangeOrReferenceResultID returns the monikers attached to the range or reference result
 // with the given identifier.
 func findMonikersByRangeOrReferenceResultID(w *capturingWriter, id uint64) (monikers []protocol.Moniker) {
@@ -23,6 +23,7 @@ type implDef struct {
 
 type methodInfo struct {
 	definition        *DefinitionInfo
+	monikerPackage    string
 	monikerIdentifier string
 }
 
@@ -55,8 +56,8 @@ func (rel implRelation) forEachImplementation(f func(from implDef, to []implDef)
 		m[e.from] = append(m[e.from], rel.nodes[e.to])
 	}
 
-	for fromi, tos := range m {
-		f(rel.nodes[fromi], tos)
+	for _, fromi := range sortedKeys(m) {
+		f(rel.nodes[fromi], m[fromi])
 	}
 }
 
@@ -165,8 +166,10 @@ func (i *Indexer) indexImplementations() error {
 		// NOTES:
 		// - We do not need to connect RemoteTypes and RemoteInterfaces because those connections will
 		//   be made when we index those projects.
-		// - We do not connect Interfaces w/ Interfaces or Types w/ Types, so there is no need to make those
-		//   connectsion between the local and remote interfaces/types.
+		// - We do not connect Types w/ Types, so there is no need to make those connections between
+		//   the local and remote types. Interfaces are connected w/ Interfaces only if requested (see
+		//   buildInterfaceImplementationRelation), in which case the same connections are made: in
+		//   particular, RemoteInterfaces are not connected w/ RemoteInterfaces.
 
 		// =========================
 		// Local Implementations
@@ -176,13 +179,30 @@ func (i *Indexer) indexImplementations() error {
 			return
 		}
 
+		// Both directions of each relation are merged so that every result set has
+		// a single implementation result
+		localImplementations := localImplementations{}
+
 		// LocalConcreteTypes -> LocalInterfaces
 		localRelation := buildImplementationRelation(localConcreteTypes, localInterfaces)
-		localRelation.forEachImplementation(i.emitLocalImplementation)
+		localRelation.forEachImplementation(localImplementations.add)
 
 		// LocalInterfaces -> LocalConcreteTypes
 		invertedLocalRelation := localRelation.invert()
-		invertedLocalRelation.forEachImplementation(i.emitLocalImplementation)
+		invertedLocalRelation.forEachImplementation(localImplementations.add)
+
+		if i.generationOptions.EnableInterfaceImplementations {
+			// LocalInterfaces <-> LocalInterfaces
+			localInterfaceRelation := buildInterfaceImplementationRelation(localInterfaces, localInterfaces)
+			localInterfaceRelation.forEachImplementation(localImplementations.add)
+			localInterfaceRelation.invert().forEachImplementation(localImplementations.add)
+		}
+
+		i.emitLocalImplementations(localImplementations)
+
+		if i.generationOptions.EnableCallHierarchy {
+			invertedLocalRelation.forEachImplementation(i.recordMethodImplementations)
+		}
 
 		// =========================
 		// Remote Implementations
@@ -192,45 +212,62 @@ func (i *Indexer) indexImplementations() error {
 			return
 		}
 
+		// NOTE: filterToExported modifies its argument, so each list is filtered only once
+		exportedRemoteInterfaces := filterToExported(remoteInterfaces)
+		exportedRemoteConcreteTypes := filterToExported(remoteConcreteTypes)
+
 		// LocalConcreteTypes -> RemoteInterfaces (exported only)
-		localTypesToRemoteInterfaces := buildImplementationRelation(localConcreteTypes, filterToExported(remoteInterfaces))
+		localTypesToRemoteInterfaces := buildImplementationRelation(localConcreteTypes, exportedRemoteInterfaces)
 		localTypesToRemoteInterfaces.forEachImplementation(i.emitRemoteImplementation)
 
+		if i.generationOptions.EnableCallHierarchy {
+			localTypesToRemoteInterfaces.invert().forEachImplementation(i.recordMethodImplementations)
+		}
+
 		// RemoteConcreteTypes (exported only) -> LocalInterfaces
-		localInterfacesToRemoteTypes := buildImplementationRelation(filterToExported(remoteConcreteTypes), localInterfaces).invert()
+		localInterfacesToRemoteTypes := buildImplementationRelation(exportedRemoteConcreteTypes, localInterfaces).invert()
 		localInterfacesToRemoteTypes.forEachImplementation(i.emitRemoteImplementation)
 
+		if i.generationOptions.EnableCallHierarchy {
+			localInterfacesToRemoteTypes.forEachImplementation(i.recordMethodImplementations)
+		}
+
+		if i.generationOptions.EnableInterfaceImplementations {
+			// LocalInterfaces -> RemoteInterfaces (exported only)
+			buildInterfaceImplementationRelation(localInterfaces, exportedRemoteInterfaces).forEachImplementation(i.emitRemoteImplementation)
+
+			// RemoteInterfaces (exported only) -> LocalInterfaces
+			buildInterfaceImplementationRelation(exportedRemoteInterfaces, localInterfaces).invert().forEachImplementation(i.emitRemoteImplementation)
+		}
 	}, i.outputOptions)
 
 	return implErr
 }
 
-// emitLocalImplementation correlates implementations for both structs/interfaces (refered to as typeDefs) and methods.
-func (i *Indexer) emitLocalImplementation(from implDef, tos []implDef) {
-	typeDefDocToInVs := map[uint64][]uint64{}
-	for _, to := range tos {
-		if to.defInfo == nil {
-			continue
-		}
+// localImplementations maps the result set of each type and method to the ranges of its
+// implementations, grouped by document.
+type localImplementations map[uint64]map[uint64][]uint64 // resultSetID -> documentID -> rangeIDs
 
-		documentID := to.defInfo.DocumentID
+// add correlates implementations for both structs/interfaces (refered to as typeDefs) and methods.
+func (impls localImplementations) add(from implDef, tos []implDef) {
+	if from.defInfo != nil {
+		// Record implementation for the typeDefs directly
+		impls.ensure(from.defInfo.ResultSetID)
 
-		if _, ok := typeDefDocToInVs[documentID]; !ok {
-			typeDefDocToInVs[documentID] = []uint64{}
-		}
-		typeDefDocToInVs[documentID] = append(typeDefDocToInVs[documentID], to.defInfo.RangeID)
-	}
+		for _, to := range tos {
+			if to.defInfo == nil {
+				continue
+			}
 
-	if from.defInfo != nil {
-		// Emit implementation for the typeDefs directly
-		i.emitLocalImplementationRelation(from.defInfo.ResultSetID, typeDefDocToInVs)
+			impls.addItem(from.defInfo.ResultSetID, to.defInfo)
+		}
 	}
 
-	// Emit implementation for each of the methods on typeDefs
-	for fromName, fromMethod := range from.methodsByName {
-		methodDocToInvs := map[uint64][]uint64{}
+	// Record implementation for each of the methods on typeDefs
+	for _, fromName := range sortedKeys(from.methodsByName) {
+		fromMethod := from.methodsByName[fromName]
 
-		fromMethodDef := i.forEachMethodImplementation(tos, fromName, fromMethod, func(to implDef, _ *DefinitionInfo) {
+		fromMethodDef := forEachMethodImplementation(tos, fromName, fromMethod, func(to implDef, fromDef *DefinitionInfo) {
 			toMethod := to.methodsByName[fromName]
 
 			// This method is from an embedded type defined in some dependency.
@@ -238,18 +275,45 @@ func (i *Indexer) emitLocalImplementation(from implDef, tos []implDef) {
 				return
 			}
 
-			toDocument := toMethod.definition.DocumentID
-			if _, ok := methodDocToInvs[toDocument]; !ok {
-				methodDocToInvs[toDocument] = []uint64{}
-			}
-			methodDocToInvs[toDocument] = append(methodDocToInvs[toDocument], toMethod.definition.RangeID)
+			impls.addItem(fromDef.ResultSetID, toMethod.definition)
 		})
 
 		if fromMethodDef == nil {
 			continue
 		}
 
-		i.emitLocalImplementationRelation(fromMethodDef.ResultSetID, methodDocToInvs)
+		impls.ensure(fromMethodDef.ResultSetID)
+	}
+}
+
+// ensure records the given result set, which has an implementation result even if it has no items.
+func (impls localImplementations) ensure(resultSetID uint64) map[uint64][]uint64 {
+	documentToInVs, ok := impls[resultSetID]
+	if !ok {
+		documentToInVs = map[uint64][]uint64{}
+		impls[resultSetID] = documentToInVs
+	}
+
+	return documentToInVs
+}
+
+// addItem records the range of the given definition as an implementation of the given result set.
+func (impls localImplementations) addItem(resultSetID uint64, d *DefinitionInfo) {
+	documentToInVs := impls.ensure(resultSetID)
+
+	for _, rangeID := range documentToInVs[d.DocumentID] {
+		if rangeID == d.RangeID {
+			return
+		}
+	}
+
+	documentToInVs[d.DocumentID] = append(documentToInVs[d.DocumentID], d.RangeID)
+}
+
+// emitLocalImplementations emits the required LSIF nodes for each recorded implementation
+func (i *Indexer) emitLocalImplementations(impls localImplementations) {
+	for _, defResultSetID := range sortedKeys(impls) {
+		i.emitLocalImplementationRelation(defResultSetID, impls[defResultSetID])
 	}
 }
 
@@ -258,8 +322,8 @@ func (i *Indexer) emitLocalImplementationRelation(defResultSetID uint64, documen
 	implResultID := i.emitter.EmitImplementationResult()
 	i.emitter.EmitTextDocumentImplementation(defResultSetID, implResultID)
 
-	for documentID, inVs := range documentToInVs {
-		i.emitter.EmitItem(implResultID, inVs, documentID)
+	for _, documentID := range sortedKeys(documentToInVs) {
+		i.emitter.EmitItem(implResultID, documentToInVs[documentID], documentID)
 	}
 }
 
@@ -273,8 +337,9 @@ func (i *Indexer) emitRemoteImplementation(from implDef, tos []implDef) {
 		i.emitImplementationMoniker(from.defInfo.ResultSetID, to.monikerPackage, to.monikerIdentifier)
 	}
 
-	for fromName, fromMethod := range from.methodsByName {
-		i.forEachMethodImplementation(tos, fromName, fromMethod, func(to implDef, fromDef *DefinitionInfo) {
+	for _, fromName := range sortedKeys(from.methodsByName) {
+		fromMethod := from.methodsByName[fromName]
+		forEachMethodImplementation(tos, fromName, fromMethod, func(to implDef, fromDef *DefinitionInfo) {
 			toMethod := to.methodsByName[fromName]
 			i.emitImplementationMoniker(fromDef.ResultSetID, to.monikerPackage, toMethod.monikerIdentifier)
 		})
@@ -286,7 +351,7 @@ func (i *Indexer) emitRemoteImplementation(from implDef, tos []implDef) {
 //
 // It returns the definition of the method that can be linked for each of the
 // associated tos
-func (i *Indexer) forEachMethodImplementation(
+func forEachMethodImplementation(
 	tos []implDef,
 	fromName string,
 	fromMethod methodInfo,
@@ -323,11 +388,19 @@ func (i *Indexer) forEachMethodImplementation(
 // concrete types from the list of given packages.
 func (i *Indexer) extractInterfacesAndConcreteTypes(pkgNames []string) (interfaces []implDef, concreteTypes []implDef, err error) {
 	visit := func(pkg *packages.Package) {
-		for ident, obj := range pkg.TypesInfo.Defs {
+		for _, ident := range identsOf(pkg.TypesInfo.Defs, i.generationOptions.Deterministic) {
+			obj := pkg.TypesInfo.Defs[ident]
 			if obj == nil {
 				continue
 			}
 
+			// Skip project types in files that are indexed through the package of
+			// another build configuration, which reports the same type
+			filename := pkg.Fset.Position(ident.Pos()).Filename
+			if packages := i.packagesByFile[filename]; len(packages) > 0 && packages[0] != pkg {
+				continue
+			}
+
 			// We ignore aliases 'type M = N' to avoid duplicate reporting
 			// of the Named type N.
 			typeName, ok := obj.(*types.TypeName)
@@ -354,9 +427,12 @@ func (i *Indexer) extractInterfacesAndConcreteTypes(pkgNames []string) (interfac
 
 			methodsByName := map[string]methodInfo{}
 			for _, m := range methods {
+				methodMonikerPackage := makeMonikerPackage(m.Obj())
+
 				methodsByName[m.Obj().Name()] = methodInfo{
 					definition:        i.getDefinitionInfo(m.Obj(), nil),
-					monikerIdentifier: joinMonikerParts(makeMonikerPackage(m.Obj()), makeMonikerIdentifier(i.packageDataCache, pkg, m.Obj())),
+					monikerPackage:    methodMonikerPackage,
+					monikerIdentifier: joinMonikerParts(methodMonikerPackage, makeMonikerIdentifier(i.packageDataCache, pkg, m.Obj())),
 				}
 			}
 
@@ -416,7 +492,7 @@ func (i *Indexer) extractInterfacesAndConcreteTypes(pkgNames []string) (interfac
 func buildImplementationRelation(concreteTypes, interfaces []implDef) implRelation {
 	rel := implRelation{
 		edges:       []implEdge{},
-		nodes:       append(concreteTypes, interfaces...),
+		nodes:       append(append([]implDef(nil), concreteTypes...), interfaces...),
 		ifaceOffset: len(concreteTypes),
 	}
 
@@ -439,6 +515,31 @@ func buildImplementationRelation(concreteTypes, interfaces []implDef) implRelati
 	return rel
 }
 
+// buildInterfaceImplementationRelation builds a map from interfaces to all the other interfaces that
+// they implement, which are the interfaces whose method sets are subsets of theirs (e.g. io.ReadWriter
+// implements io.Reader). Aliases are skipped because they are the same interface as the aliased one.
+func buildInterfaceImplementationRelation(interfaces, otherInterfaces []implDef) implRelation {
+	rel := buildImplementationRelation(interfaces, otherInterfaces)
+
+	edges := rel.edges[:0]
+	for _, e := range rel.edges {
+		from, to := rel.nodes[e.from], rel.nodes[e.to]
+		if from.typeNameIsAlias || to.typeNameIsAlias {
+			continue
+		}
+
+		// Every interface implements itself
+		if from.defInfo == to.defInfo && from.monikerIdentifier == to.monikerIdentifier {
+			continue
+		}
+
+		edges = append(edges, e)
+	}
+	rel.edges = edges
+
+	return rel
+}
+
 // listMethods returns the method set for a named type T
 // merged with all the methods of *T that have different names than
 // the methods of T.
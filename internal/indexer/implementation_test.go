@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestInterfaceImplementations(t *testing.T) {
+	definition := func(id uint64) *DefinitionInfo {
+		return &DefinitionInfo{DocumentID: 1, RangeID: id, ResultSetID: id + 100}
+	}
+
+	method := func(id uint64) methodInfo {
+		return methodInfo{definition: definition(id)}
+	}
+
+	reader := implDef{
+		defInfo:           definition(1),
+		methods:           []string{"Read()"},
+		methodsByName:     map[string]methodInfo{"Read": method(2)},
+		monikerIdentifier: "example.com/main:Reader",
+	}
+	readWriter := implDef{
+		defInfo:           definition(3),
+		methods:           []string{"Read()", "Write()"},
+		methodsByName:     map[string]methodInfo{"Read": method(4), "Write": method(5)},
+		monikerIdentifier: "example.com/main:ReadWriter",
+	}
+	readWriterAlias := implDef{
+		defInfo:           definition(6),
+		methods:           []string{"Read()", "Write()"},
+		methodsByName:     map[string]methodInfo{"Read": method(4), "Write": method(5)},
+		monikerIdentifier: "example.com/main:RW",
+		typeNameIsAlias:   true,
+	}
+	file := implDef{
+		defInfo:           definition(7),
+		methods:           []string{"Read()", "Write()"},
+		methodsByName:     map[string]methodInfo{"Read": method(8), "Write": method(9)},
+		monikerIdentifier: "example.com/main:File",
+	}
+
+	interfaces := []implDef{reader, readWriter, readWriterAlias}
+	concreteTypes := []implDef{file}
+
+	interfaceRelation := buildInterfaceImplementationRelation(interfaces, interfaces)
+	var edges [][2]int
+	for _, e := range interfaceRelation.edges {
+		edges = append(edges, [2]int{e.from, e.to})
+	}
+	if diff := cmp.Diff([][2]int{{1, 3}}, edges); diff != "" {
+		t.Errorf("unexpected interface implementation edges (-want +got): %s", diff)
+	}
+
+	impls := localImplementations{}
+	relation := buildImplementationRelation(concreteTypes, interfaces[:2])
+	relation.forEachImplementation(impls.add)
+	relation.invert().forEachImplementation(impls.add)
+	interfaceRelation.forEachImplementation(impls.add)
+	interfaceRelation.invert().forEachImplementation(impls.add)
+
+	expected := localImplementations{
+		101: {1: {7, 3}},           // Reader -> File, ReadWriter
+		102: {1: {8, 4}},           // Reader.Read -> File.Read, ReadWriter.Read
+		103: {1: {7, 1}},           // ReadWriter -> File, Reader
+		104: {1: {8, 2}},           // ReadWriter.Read -> File.Read, Reader.Read
+		105: {1: {9}},              // ReadWriter.Write -> File.Write
+		107: {1: {1, 3}},           // File -> Reader, ReadWriter
+		108: {1: {2, 4}},           // File.Read -> Reader.Read, ReadWriter.Read
+		109: map[uint64][]uint64{}, // File.Write is not a method of Reader
+	}
+	if diff := cmp.Diff(expected, impls); diff != "" {
+		t.Errorf("unexpected implementations (-want +got): %s", diff)
+	}
+}
+
+func TestIndexInterfaceImplementations(t *testing.T) {
+	uri := "file://" + filepath.Join(getRepositoryRoot(t), "fixtures", "hierarchies.go")
+
+	for _, testCase := range []struct {
+		name                           string
+		enableInterfaceImplementations bool
+		line, character                int
+		expected                       []string
+	}{
+		{"Shape", false, 5, 5, []string{"16:5-16:11", "25:5-25:9"}},
+		{"Polygon", false, 10, 5, []string{"16:5-16:11", "25:5-25:9"}},
+		{"Shape with interface implementations", true, 5, 5, []string{"10:5-10:12", "16:5-16:11", "25:5-25:9"}},
+		{"Polygon with interface implementations", true, 10, 5, []string{"5:5-5:10", "16:5-16:11", "25:5-25:9"}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			w := &capturingWriter{
+				ranges:    map[uint64]protocol.Range{},
+				documents: map[uint64]protocol.Document{},
+				contains:  map[uint64]uint64{},
+			}
+
+			generationOptions := NewGenerationOptions()
+			generationOptions.EnableInterfaceImplementations = testCase.enableInterfaceImplementations
+			indexFixtures(t, w, generationOptions)
+
+			r := mustRange(t, w, uri, testCase.line, testCase.character)
+			assertRanges(t, w, findImplementationRangesByRangeOrResultSetID(w, r.ID), testCase.expected, testCase.name+" implementations")
+		})
+	}
+}
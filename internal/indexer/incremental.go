@@ -0,0 +1,509 @@
+package indexer
+
+import (
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"golang.org/x/tools/go/packages"
+)
+
+// PreviousDump is an earlier dump of the project along with the files that have changed
+// since the revision it was generated from.
+type PreviousDump struct {
+	Elements     []interface{} // the elements of the dump, as returned by dump.Read
+	ChangedFiles []string      // absolute paths of added, modified, and deleted files
+}
+
+// moduleFiles are files that can change the result of type checking every package.
+var moduleFiles = map[string]struct{}{
+	"go.mod":  {},
+	"go.sum":  {},
+	"go.work": {},
+}
+
+// previousIndex is the subset of a previous dump's graph that is needed to copy the data
+// of unchanged packages.
+type previousIndex struct {
+	documents          map[string]uint64         // uri -> documentID
+	documentRanges     map[uint64][]uint64       // documentID -> rangeIDs
+	ranges             map[uint64]protocol.Range // rangeID -> range
+	next               map[uint64]uint64         // rangeID -> resultSetID
+	hovers             map[uint64]uint64         // rangeID or resultSetID -> hoverResultID
+	definitions        map[uint64]uint64         // rangeID or resultSetID -> definitionResultID
+	definitionSets     map[uint64]uint64         // definitionResultID -> resultSetID
+	items              map[uint64][]uint64       // definitionResultID -> rangeIDs
+	monikerEdges       map[uint64][]uint64       // resultSetID -> monikerIDs
+	hoverResults       map[uint64]protocol.HoverResult
+	monikers           map[uint64]protocol.Moniker
+	packageInformation map[uint64]protocol.PackageInformation
+	monikerPackages    map[uint64]uint64 // monikerID -> packageInformationID
+}
+
+func newPreviousIndex(elements []interface{}) *previousIndex {
+	p := &previousIndex{
+		documents:          map[string]uint64{},
+		documentRanges:     map[uint64][]uint64{},
+		ranges:             map[uint64]protocol.Range{},
+		next:               map[uint64]uint64{},
+		hovers:             map[uint64]uint64{},
+		definitions:        map[uint64]uint64{},
+		definitionSets:     map[uint64]uint64{},
+		items:              map[uint64][]uint64{},
+		monikerEdges:       map[uint64][]uint64{},
+		hoverResults:       map[uint64]protocol.HoverResult{},
+		monikers:           map[uint64]protocol.Moniker{},
+		packageInformation: map[uint64]protocol.PackageInformation{},
+		monikerPackages:    map[uint64]uint64{},
+	}
+
+	for _, element := range elements {
+		switch e := element.(type) {
+		case protocol.Document:
+			p.documents[e.URI] = e.ID
+		case protocol.Range:
+			p.ranges[e.ID] = e
+		case protocol.HoverResult:
+			p.hoverResults[e.ID] = e
+		case protocol.Moniker:
+			p.monikers[e.ID] = e
+		case protocol.PackageInformation:
+			p.packageInformation[e.ID] = e
+		case protocol.Contains:
+			p.documentRanges[e.OutV] = append(p.documentRanges[e.OutV], e.InVs...)
+		case protocol.Next:
+			p.next[e.OutV] = e.InV
+		case protocol.TextDocumentHover:
+			p.hovers[e.OutV] = e.InV
+		case protocol.TextDocumentDefinition:
+			p.definitions[e.OutV] = e.InV
+			if _, isRange := p.ranges[e.OutV]; !isRange {
+				p.definitionSets[e.InV] = e.OutV
+			}
+		case protocol.Item:
+			p.items[e.OutV] = append(p.items[e.OutV], e.InVs...)
+		case protocol.MonikerEdge:
+			p.monikerEdges[e.OutV] = append(p.monikerEdges[e.OutV], e.InV)
+		case protocol.PackageInformationEdge:
+			p.monikerPackages[e.OutV] = e.InV
+		}
+	}
+
+	return p
+}
+
+// partitionPackages determines which of the loaded packages can be copied from the previous
+// dump. A package is reused when none of the files in its directory changed, all of its files
+// are documents of the previous dump, and the same holds for every project package that it
+// (transitively) imports.
+func (i *Indexer) partitionPackages() {
+	i.reused = map[*packages.Package]struct{}{}
+	if i.previous == nil {
+		return
+	}
+
+	changedDirs := map[string]struct{}{}
+	for _, filename := range i.generationOptions.Previous.ChangedFiles {
+		if _, ok := moduleFiles[filepath.Base(filename)]; ok {
+			// Dependency versions may have changed; nothing can be reused
+			return
+		}
+
+		changedDirs[filepath.Dir(filename)] = struct{}{}
+	}
+
+	projectPackages := map[string]struct{}{}
+	for _, p := range i.packages {
+		projectPackages[p.PkgPath] = struct{}{}
+	}
+
+	changed := func(p *packages.Package) bool {
+		for _, filename := range append(append([]string(nil), p.GoFiles...), p.OtherFiles...) {
+			if _, ok := changedDirs[filepath.Dir(filename)]; ok {
+				return true
+			}
+		}
+
+		for _, f := range p.Syntax {
+			filename := p.Fset.Position(f.Package).Filename
+			if !strings.HasPrefix(filename, i.projectRoot) {
+				// Not indexed (see emitDocument)
+				continue
+			}
+
+			if _, ok := i.previous.documents["file://"+filename]; !ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	affected := map[*packages.Package]bool{}
+	var isAffected func(p *packages.Package) bool
+	isAffected = func(p *packages.Package) bool {
+		if value, ok := affected[p]; ok {
+			return value
+		}
+
+		// Mark the package while visiting its imports to terminate on (invalid) import cycles
+		affected[p] = false
+
+		value := changed(p)
+		for _, path := range sortedKeys(p.Imports) {
+			if imported := p.Imports[path]; !value {
+				if _, ok := projectPackages[imported.PkgPath]; ok {
+					value = isAffected(imported)
+				}
+			}
+		}
+
+		affected[p] = value
+		return value
+	}
+
+	for _, p := range i.packages {
+		if !isAffected(p) {
+			i.reused[p] = struct{}{}
+		}
+	}
+}
+
+// indexedPackages returns the packages that are not copied from the previous dump.
+func (i *Indexer) indexedPackages() []*packages.Package {
+	if len(i.reused) == 0 {
+		return i.packages
+	}
+
+	indexed := make([]*packages.Package, 0, len(i.packages)-len(i.reused))
+	for _, p := range i.packages {
+		if _, ok := i.reused[p]; !ok {
+			indexed = append(indexed, p)
+		}
+	}
+
+	return indexed
+}
+
+// copyReusedPackages copies the ranges of the documents of reused packages from the previous
+// dump along with their result sets, definition results, hover text, and export monikers. The
+// copied definitions are registered like indexed definitions so that references from indexed
+// packages link to them, and reference results, implementation results, import moniker result
+// sets, and contains relations are emitted for copied data in the same way as for indexed data.
+func (i *Indexer) copyReusedPackages() {
+	if len(i.reused) == 0 {
+		return
+	}
+
+	c := &copier{
+		indexer:     i,
+		ranges:      map[uint64]uint64{},
+		documents:   map[uint64]*DocumentInfo{},
+		hovers:      map[uint64]uint64{},
+		definitions: map[uint64]*DefinitionInfo{},
+	}
+
+	var filenames []string
+	files := map[string]*token.File{}
+	for p := range i.reused {
+		for _, f := range p.Syntax {
+			filename := p.Fset.Position(f.Package).Filename
+			if packages := i.packagesByFile[filename]; len(packages) > 0 && packages[0] == p {
+				filenames = append(filenames, filename)
+				files[filename] = p.Fset.File(f.Package)
+			}
+		}
+	}
+	sort.Strings(filenames)
+
+	// Copy all ranges first so that result sets can refer to ranges in any copied document
+	var rangeIDs []uint64
+	for _, filename := range filenames {
+		document, ok := i.documents[filename]
+		if !ok {
+			continue
+		}
+
+		oldRangeIDs := append([]uint64(nil), i.previous.documentRanges[i.previous.documents["file://"+filename]]...)
+		sort.Slice(oldRangeIDs, func(j, k int) bool { return oldRangeIDs[j] < oldRangeIDs[k] })
+
+		for _, oldRangeID := range oldRangeIDs {
+			r, ok := i.previous.ranges[oldRangeID]
+			if !ok {
+				continue
+			}
+
+			c.ranges[oldRangeID] = i.emitter.EmitRange(r.Start, r.End)
+			c.documents[oldRangeID] = document
+			rangeIDs = append(rangeIDs, oldRangeID)
+
+			// Register the range by position so that passes over all loaded packages (e.g. the
+			// call hierarchy) can find the ranges of copied documents in the same way
+			if position, ok := positionOf(files[filename], r.Start); ok {
+				i.setRangeForPosition(position, c.ranges[oldRangeID])
+			}
+		}
+	}
+
+	for _, oldRangeID := range rangeIDs {
+		c.linkRange(oldRangeID)
+	}
+
+	// Definitions that cannot be looked up by object (e.g. package declarations) are not
+	// registered, and have no reference results, as in a full index
+	for _, p := range i.packages {
+		if _, ok := i.reused[p]; ok {
+			i.registerCopiedDefinitions(p, c)
+		}
+	}
+}
+
+// registerCopiedDefinitions stashes the copied definition info for each definition of the given
+// package so that it can be retrieved by getDefinitionInfo in the same way as indexed definitions.
+// Named imports and the symbolic variables of type switch headers are registered as they are by
+// emitImports and indexDefinitions.
+func (i *Indexer) registerCopiedDefinitions(p *packages.Package, c *copier) {
+	caseClauses := map[token.Pos]types.Object{}
+	for _, node := range caseClausesOf(p.TypesInfo.Implicits, i.generationOptions.Deterministic) {
+		if obj := p.TypesInfo.Implicits[node]; caseClauses[obj.Pos()] == nil {
+			caseClauses[obj.Pos()] = obj
+		}
+	}
+
+	for _, ident := range identsOf(p.TypesInfo.Defs, i.generationOptions.Deterministic) {
+		obj := p.TypesInfo.Defs[ident]
+
+		typeSwitchHeader := false
+		if obj == nil {
+			caseClause, ok := caseClauses[ident.Pos()]
+			if !ok {
+				continue
+			}
+
+			obj = caseClause
+			typeSwitchHeader = true
+		}
+
+		position, document, ok := i.positionAndDocument(p, obj.Pos())
+		if !ok || document == nil {
+			continue
+		}
+
+		start := protocol.Pos{Line: position.Line - 1, Character: position.Column - 1}
+		for _, oldRangeID := range i.previous.documentRanges[i.previous.documents["file://"+position.Filename]] {
+			if r := i.previous.ranges[oldRangeID]; r.Start != start {
+				continue
+			}
+
+			if d, ok := c.definitions[i.previous.next[oldRangeID]]; ok && d.RangeID == c.ranges[oldRangeID] {
+				d.TypeSwitchHeader = typeSwitchHeader
+				i.setDefinitionInfo(obj, ident, d)
+				break
+			}
+		}
+	}
+}
+
+// positionOf returns the position of the given line and (byte) character in the given file. This
+// method returns false if the file does not have such a line.
+func positionOf(file *token.File, pos protocol.Pos) (token.Position, bool) {
+	if file == nil || pos.Line < 0 || pos.Line >= file.LineCount() {
+		return token.Position{}, false
+	}
+
+	offset := file.Offset(file.LineStart(pos.Line+1)) + pos.Character
+	if offset > file.Size() {
+		return token.Position{}, false
+	}
+
+	return file.Position(file.Pos(offset)), true
+}
+
+// copier translates the identifiers of the previous dump into identifiers of the current one.
+type copier struct {
+	indexer     *Indexer
+	ranges      map[uint64]uint64          // old rangeID -> new rangeID
+	documents   map[uint64]*DocumentInfo   // old rangeID -> document containing the range
+	hovers      map[uint64]uint64          // old hoverResultID -> new hoverResultID
+	definitions map[uint64]*DefinitionInfo // old resultSetID -> copied definition
+}
+
+// linkRange copies the edges of the given (already copied) range.
+func (c *copier) linkRange(oldRangeID uint64) {
+	i, previous := c.indexer, c.indexer.previous
+	rangeID, document := c.ranges[oldRangeID], c.documents[oldRangeID]
+
+	if hoverResultID, ok := c.copyHover(oldRangeID); ok {
+		_ = i.emitter.EmitTextDocumentHover(rangeID, hoverResultID)
+	}
+
+	isDefinition := false
+	if oldResultSetID, ok := previous.next[oldRangeID]; ok {
+		if d := c.copyDefinition(oldResultSetID); d != nil {
+			_ = i.emitter.EmitNext(rangeID, d.ResultSetID)
+
+			if d.RangeID == rangeID {
+				isDefinition = true
+			} else {
+				d.ReferenceRangeIDs[document.DocumentID] = append(d.ReferenceRangeIDs[document.DocumentID], rangeID)
+			}
+		} else {
+			c.copyImportMonikers(oldResultSetID, rangeID, document)
+		}
+	}
+
+	if oldDefinitionResultID, ok := previous.definitions[oldRangeID]; ok {
+		// A definition edge attached directly to a range that overlaps a definition
+		// (see indexReferenceToDefinition)
+		if d := c.copyDefinition(previous.definitionSets[oldDefinitionResultID]); d != nil {
+			_ = i.emitter.EmitTextDocumentDefinition(rangeID, d.DefinitionResultID)
+			d.ReferenceRangeIDs[document.DocumentID] = append(d.ReferenceRangeIDs[document.DocumentID], rangeID)
+		}
+	}
+
+	for _, oldMonikerID := range previous.monikerEdges[oldRangeID] {
+		// An import moniker attached directly to a range that overlaps a definition
+		// (see indexReferenceToExternalDefinition)
+		moniker := previous.monikers[oldMonikerID]
+		if moniker.Kind != "import" {
+			continue
+		}
+
+		if packageInformationID, ok := c.copyPackageInformation(oldMonikerID); ok {
+			_ = i.emitter.EmitMonikerEdge(rangeID, i.ensureImportMoniker(moniker.Identifier, packageInformationID, 0))
+		}
+	}
+
+	if isDefinition {
+		document.appendDefinition(rangeID)
+	} else {
+		document.appendReference(rangeID)
+	}
+}
+
+// copyDefinition copies the given result set along with its definition result, hover text,
+// and export monikers. This method returns nil if the result set does not belong to a
+// definition within a copied document.
+func (c *copier) copyDefinition(oldResultSetID uint64) *DefinitionInfo {
+	if d, ok := c.definitions[oldResultSetID]; ok {
+		return d
+	}
+
+	i, previous := c.indexer, c.indexer.previous
+
+	oldDefinitionResultID, ok := previous.definitions[oldResultSetID]
+	if !ok {
+		return nil
+	}
+	oldRangeIDs := previous.items[oldDefinitionResultID]
+	if len(oldRangeIDs) == 0 {
+		return nil
+	}
+	rangeID, ok := c.ranges[oldRangeIDs[0]]
+	if !ok {
+		return nil
+	}
+	document := c.documents[oldRangeIDs[0]]
+
+	resultSetID := i.emitter.EmitResultSet()
+	definitionResultID := i.emitter.EmitDefinitionResult()
+	_ = i.emitter.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+	_ = i.emitter.EmitItem(definitionResultID, []uint64{rangeID}, document.DocumentID)
+
+	if hoverResultID, ok := c.copyHover(oldResultSetID); ok {
+		_ = i.emitter.EmitTextDocumentHover(resultSetID, hoverResultID)
+	}
+
+	for _, oldMonikerID := range previous.monikerEdges[oldResultSetID] {
+		moniker := previous.monikers[oldMonikerID]
+		if moniker.Kind != "export" {
+			// Implementation monikers are emitted again by indexImplementations
+			continue
+		}
+
+		monikerID := i.emitter.EmitMoniker(moniker.Kind, moniker.Scheme, moniker.Identifier)
+		if packageInformationID, ok := c.copyPackageInformation(oldMonikerID); ok {
+			_ = i.emitter.EmitPackageInformationEdge(monikerID, packageInformationID)
+		}
+		_ = i.emitter.EmitMonikerEdge(resultSetID, monikerID)
+	}
+
+	d := &DefinitionInfo{
+		DocumentID:         document.DocumentID,
+		RangeID:            rangeID,
+		ResultSetID:        resultSetID,
+		DefinitionResultID: definitionResultID,
+		ReferenceRangeIDs:  map[uint64][]uint64{},
+	}
+	c.definitions[oldResultSetID] = d
+	return d
+}
+
+// copyImportMonikers registers the given range as a reference to each import moniker attached
+// to the given result set. The result sets of import monikers are emitted again by
+// linkImportMonikersToRanges.
+func (c *copier) copyImportMonikers(oldResultSetID, rangeID uint64, document *DocumentInfo) {
+	i, previous := c.indexer, c.indexer.previous
+
+	for _, oldMonikerID := range previous.monikerEdges[oldResultSetID] {
+		moniker := previous.monikers[oldMonikerID]
+		if moniker.Kind != "import" {
+			continue
+		}
+
+		// The kinds of the objects named by the monikers of a previous dump are unknown
+		if packageInformationID, ok := c.copyPackageInformation(oldMonikerID); ok {
+			i.addImportMonikerReference(i.ensureImportMoniker(moniker.Identifier, packageInformationID, 0), rangeID, document.DocumentID)
+		}
+	}
+}
+
+// copyPackageInformation returns the package information vertex in the current dump that
+// corresponds to the package information of the given moniker. The version of the current
+// module (or workspace modules) is used in place of the version recorded in the previous dump.
+func (c *copier) copyPackageInformation(oldMonikerID uint64) (uint64, bool) {
+	i, previous := c.indexer, c.indexer.previous
+
+	packageInformation, ok := previous.packageInformation[previous.monikerPackages[oldMonikerID]]
+	if !ok {
+		return 0, false
+	}
+
+	version := packageInformation.Version
+	if i.isProjectModule(packageInformation.Name) {
+		version = i.moduleVersion
+	}
+
+	return i.ensurePackageInformation(packageInformation.Name, version), true
+}
+
+// copyHover returns the hover result in the current dump that corresponds to the hover
+// result attached to the given range or result set.
+func (c *copier) copyHover(oldID uint64) (uint64, bool) {
+	i, previous := c.indexer, c.indexer.previous
+
+	oldHoverResultID, ok := previous.hovers[oldID]
+	if !ok {
+		return 0, false
+	}
+	if hoverResultID, ok := c.hovers[oldHoverResultID]; ok {
+		return hoverResultID, true
+	}
+
+	hoverResult, ok := previous.hoverResults[oldHoverResultID]
+	if !ok {
+		return 0, false
+	}
+	text, err := dump.HoverText(hoverResult.Result.Contents)
+	if err != nil {
+		return 0, false
+	}
+
+	hoverResultID := i.emitter.EmitHoverResult(protocol.NewMarkupContent(text, protocol.Markdown))
+	c.hovers[oldHoverResultID] = hoverResultID
+	return hoverResultID, true
+}
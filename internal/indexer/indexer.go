@@ -10,6 +10,8 @@ import (
 	"log"
 	"math"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -18,7 +20,7 @@ import (
 	"github.com/sourcegraph/lsif-go/internal/gomod"
 	"github.com/sourcegraph/lsif-go/internal/output"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
-	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -32,12 +34,64 @@ type setVal interface{}
 type GenerationOptions struct {
 	EnableImplementations bool
 	DepBatchSize          int
+
+	// EnableCallHierarchy emits an edge from each function to each function that it calls.
+	// Calls of interface methods are linked to the implementations of the method, which are
+	// only known if EnableImplementations is also set.
+	EnableCallHierarchy bool
+
+	// EnableInterfaceImplementations also links interfaces to the interfaces that they
+	// implement, which are the interfaces whose method sets are subsets of theirs. Two
+	// interfaces declared in dependencies are not linked to each other. This requires
+	// EnableImplementations.
+	EnableInterfaceImplementations bool
+
+	// EnableUnexportedMonikers attaches monikers to unexported definitions that are
+	// not local to a function body. Output formats such as SCIP require a global name
+	// for every symbol that can be referenced from another document.
+	EnableUnexportedMonikers bool
+
+	// EnableMonikerSymbolKinds records the kind of the object named by each import and
+	// export moniker. Output formats such as SCIP encode the kind in the name of each
+	// symbol. The kinds are not part of LSIF dumps.
+	EnableMonikerSymbolKinds bool
+
+	// Deterministic visits packages, definitions, and documents one at a time and in a
+	// stable order so that indexing the same source twice produces byte-identical output.
+	// This gives up the concurrency of the indexer and is considerably slower.
+	Deterministic bool
+
+	// Previous is an earlier dump of the project. When set, packages that are not affected
+	// by the changed files are copied from the previous dump instead of being indexed again.
+	Previous *PreviousDump
+
+	// WorkspaceModules are the modules of the go.work workspace in the project root. When set,
+	// the packages of every module are indexed together and each module is emitted as its own
+	// project with its own package information. References between modules of the workspace
+	// resolve to the local definitions.
+	WorkspaceModules []gomod.WorkspaceModule
+
+	// BuildConfigs are the platforms for which the project packages are loaded. Files that
+	// are part of several configurations are indexed once, and definitions in such files are
+	// shared by the references of every configuration. The host platform is used if empty.
+	BuildConfigs []BuildConfig
+
+	// BuildTags are the additional build tags that are satisfied while loading packages.
+	BuildTags []string
+
+	// Analyzers are run over the project packages after they are loaded. Their findings
+	// are emitted as diagnostics of the documents in which they are reported.
+	Analyzers []*analysis.Analyzer
 }
 
 func NewGenerationOptions() GenerationOptions {
 	return GenerationOptions{
-		EnableImplementations: true,
-		DepBatchSize:          0,
+		EnableImplementations:          true,
+		EnableCallHierarchy:            false,
+		EnableInterfaceImplementations: false,
+		DepBatchSize:                   0,
+		EnableUnexportedMonikers:       false,
+		Deterministic:                  false,
 	}
 }
 
@@ -50,7 +104,7 @@ type Indexer struct {
 	moduleVersion       string                    // version of this module
 	dependencies        map[string]gomod.GoModule // parsed module data
 	projectDependencies []string                  // packages that this package depends on
-	emitter             *writer.Emitter           // LSIF data emitter
+	emitter             Emitter                   // LSIF data emitter
 	outputOptions       output.Options            // What to print to stdout/stderr
 
 	// Definition type cache
@@ -68,12 +122,20 @@ type Indexer struct {
 	hoverResultCache                         map[string]uint64                       // cache key -> hoverResultID
 	importMonikerIDs                         map[string]uint64                       // identifier:packageInformationID -> monikerID
 	implementationMonikerIDs                 map[string]uint64                       // identifier:packageInformationID -> monikerID
+	monikerResultSetIDs                      map[uint64]uint64                       // monikerID -> resultSetID
 	importMonikerReferences                  map[uint64]map[uint64]map[uint64]setVal // monikerKey -> documentID -> Set(rangeID)
 	packageInformationIDs                    map[string]uint64                       // name -> packageInformationID
 	packageDataCache                         *PackageDataCache                       // hover text and moniker path cache
 	packages                                 []*packages.Package                     // index target packages
 	projectID                                uint64                                  // project vertex identifier
+	workspaceProjectIDs                      []uint64                                // project vertex identifier per workspace module
 	packagesByFile                           map[string][]*packages.Package
+	previous                                 *previousIndex                          // graph of the previous dump
+	reused                                   map[*packages.Package]struct{}          // packages copied from the previous dump
+	analysisFindings                         []analysisFinding                       // diagnostics reported by analyzers
+	typeDefinitionResults                    *typeDefinitionResults                  // type -> typeDefinitionResultID
+	callHierarchy                            *callHierarchy                          // implementations and result sets of callees
+	typeHierarchy                            *typeHierarchy                          // dependency types whose supertypes were emitted
 
 	constsMutex                sync.Mutex
 	funcsMutex                 sync.Mutex
@@ -84,6 +146,7 @@ type Indexer struct {
 	stripedMutex               *StripedMutex
 	hoverResultCacheMutex      sync.RWMutex
 	importMonikerIDsMutex      sync.RWMutex
+	monikerResultSetIDsMutex   sync.Mutex
 	packageInformationIDsMutex sync.RWMutex
 
 	importMonikerChannel chan importMonikerReference
@@ -100,7 +163,7 @@ func New(
 	moduleVersion string,
 	dependencies map[string]gomod.GoModule,
 	projectDependencies []string,
-	jsonWriter writer.JSONWriter,
+	emitter Emitter,
 	packageDataCache *PackageDataCache,
 	outputOptions output.Options,
 	generationOptions GenerationOptions,
@@ -114,7 +177,7 @@ func New(
 		moduleVersion:            moduleVersion,
 		dependencies:             dependencies,
 		projectDependencies:      projectDependencies,
-		emitter:                  writer.NewEmitter(jsonWriter),
+		emitter:                  emitter,
 		outputOptions:            outputOptions,
 		consts:                   map[interface{}]*DefinitionInfo{},
 		funcs:                    map[interface{}]*DefinitionInfo{},
@@ -128,6 +191,7 @@ func New(
 		hoverResultCache:         map[string]uint64{},
 		importMonikerIDs:         map[string]uint64{},
 		implementationMonikerIDs: map[string]uint64{},
+		monikerResultSetIDs:      map[uint64]uint64{},
 		importMonikerReferences:  map[uint64]map[uint64]map[uint64]setVal{},
 		packageInformationIDs:    map[string]uint64{},
 		packageDataCache:         packageDataCache,
@@ -145,6 +209,11 @@ func (i *Indexer) Index() error {
 		return errors.Wrap(err, "failed to load packages")
 	}
 
+	if i.generationOptions.Previous != nil {
+		i.previous = newPreviousIndex(i.generationOptions.Previous.Elements)
+	}
+	i.partitionPackages()
+
 	wg := new(sync.WaitGroup)
 	// Start any channels used to synchronize reference sets
 	i.startImportMonikerReferenceTracker(wg)
@@ -152,10 +221,18 @@ func (i *Indexer) Index() error {
 	// Begin emitting and indexing package
 	i.emitMetadataAndProjectVertex()
 	i.emitDocuments()
+	i.copyReusedPackages()
+	i.runAnalyzers()
+	i.emitDiagnostics()
 	i.emitImports()
 	i.indexPackageDeclarations()
 	i.indexDefinitions()
+	i.linkBuildConfigDefinitions()
 	i.indexReferences()
+	i.indexTypeDefinitions()
+	i.indexTypeHierarchy()
+	i.emitDocumentSymbols()
+	i.emitFoldingRanges()
 
 	// Stop any channels used to synchronize reference sets
 	//    Implementations needs all references to be complete.
@@ -166,6 +243,9 @@ func (i *Indexer) Index() error {
 		return errors.Wrap(err, "indexing implementations")
 	}
 
+	// Calls of interface methods are linked to the implementations found above
+	i.indexCallHierarchy()
+
 	// Link sets of items to corresponding ranges and results.
 	i.linkReferenceResultsToRanges()
 	i.linkImportMonikersToRanges()
@@ -261,33 +341,60 @@ func (i *Indexer) loadPackage(deduplicate bool, patterns ...string) ([]*packages
 	if isLoadingProject && i.packages != nil {
 		return i.packages, nil
 	}
+	if isLoadingProject && len(i.generationOptions.WorkspaceModules) > 0 {
+		patterns = gomod.WorkspacePatterns(i.projectRoot, i.generationOptions.WorkspaceModules)
+	}
+
+	// The project is loaded once per build configuration, in order, so that files shared by
+	// several configurations belong to the package of the first one in packagesByFile. The
+	// loads share a file set so that positions are comparable across configurations.
+	//
+	// Dependencies (e.g. those searched for implementations of project interfaces) are loaded
+	// for the first configuration only, so declarations that exist only in platform-specific
+	// files of a dependency for a later configuration are not found.
+	buildConfigs := i.buildConfigs()
+	if !isLoadingProject {
+		buildConfigs = buildConfigs[:1]
+	}
+	fset := token.NewFileSet()
+
+	var keep []*packages.Package
+	for _, buildConfig := range buildConfigs {
+		config := &packages.Config{
+			Mode:       loadMode,
+			Dir:        i.projectRoot,
+			Logf:       i.packagesLoadLogger,
+			Env:        buildConfig.env(),
+			BuildFlags: i.buildFlags(),
+			Fset:       fset,
+
+			// Only load tests for the current project.
+			// This greatly reduces memory usage when loading dependencies
+			Tests: isLoadingProject,
+		}
 
-	config := &packages.Config{
-		Mode: loadMode,
-		Dir:  i.projectRoot,
-		Logf: i.packagesLoadLogger,
-
-		// Only load tests for the current project.
-		// This greatly reduces memory usage when loading dependencies
-		Tests: isLoadingProject,
-	}
+		// Make sure we only load packages once per execution.
+		pkgs, err := packages.Load(config, patterns...)
+		if err != nil {
+			if buildConfig != (BuildConfig{}) {
+				return nil, errors.Wrapf(err, "packages.Load (%s)", buildConfig)
+			}
 
-	// Make sure we only load packages once per execution.
-	pkgs, err := packages.Load(config, patterns...)
-	if err != nil {
-		return nil, errors.Wrap(err, "packages.Load")
-	}
+			return nil, errors.Wrap(err, "packages.Load")
+		}
 
-	if !deduplicate {
-		return pkgs, nil
-	}
+		if !deduplicate {
+			keep = append(keep, pkgs...)
+			continue
+		}
 
-	keep := make([]*packages.Package, 0, len(pkgs))
-	for _, pkg := range pkgs {
-		if i.shouldVisitPackage(pkg, pkgs) {
-			keep = append(keep, pkg)
+		for _, pkg := range pkgs {
+			if i.shouldVisitPackage(pkg, pkgs) {
+				keep = append(keep, pkg)
+			}
 		}
 	}
+
 	return keep, nil
 }
 
@@ -357,6 +464,15 @@ func (i *Indexer) packagesLoadLogger(format string, args ...interface{}) {
 // vertex, which is needed to construct the project/document contains relation later.
 func (i *Indexer) emitMetadataAndProjectVertex() {
 	i.emitter.EmitMetaData("file://"+i.repositoryRoot, i.toolInfo)
+
+	if len(i.generationOptions.WorkspaceModules) > 0 {
+		for range i.generationOptions.WorkspaceModules {
+			i.workspaceProjectIDs = append(i.workspaceProjectIDs, i.emitter.EmitProject(languageGo))
+		}
+
+		return
+	}
+
 	i.projectID = i.emitter.EmitProject(languageGo)
 }
 
@@ -384,6 +500,12 @@ func (i *Indexer) emitDocument(filename string) {
 		return
 	}
 
+	// Vendored packages are dependencies of the project, and references to them are linked
+	// through import monikers of the upstream module (see gomod.ListDependencies)
+	if strings.HasPrefix(filename, filepath.Join(i.projectRoot, "vendor")+string(filepath.Separator)) {
+		return
+	}
+
 	documentID := i.emitter.EmitDocument(languageGo, filename)
 	i.documents[filename] = &DocumentInfo{DocumentID: documentID}
 	i.ranges[filename] = map[int]uint64{}
@@ -492,15 +614,17 @@ func (i *Indexer) indexDefinitionsForPackage(p *packages.Package) {
 	// Create a map of implicit case clause objects by their position. Note that there is an
 	// implicit object for each case clause of a type switch (including default), and they all
 	// share the same position. This creates a map with one arbitrarily chosen argument for
-	// each distinct type switch.
+	// each distinct type switch (the first case clause when generating deterministic output).
 	caseClauses := map[token.Pos]ObjectLike{}
-	for node, obj := range p.TypesInfo.Implicits {
-		if _, ok := node.(*ast.CaseClause); ok {
+	for _, node := range caseClausesOf(p.TypesInfo.Implicits, i.generationOptions.Deterministic) {
+		if obj := p.TypesInfo.Implicits[node]; caseClauses[obj.Pos()] == nil {
 			caseClauses[obj.Pos()] = obj
 		}
 	}
 
-	for ident, typeObj := range p.TypesInfo.Defs {
+	for _, ident := range identsOf(p.TypesInfo.Defs, i.generationOptions.Deterministic) {
+		typeObj := p.TypesInfo.Defs[ident]
+
 		// Must cast because other we have errors from being unable to assign
 		// an ObjectLike to a types.Object due to missing things like `color` and other
 		// private methods.
@@ -650,7 +774,7 @@ func (i *Indexer) indexDefinitionForRangeAndResult(p *packages.Package, document
 
 	// NOTE: Import monikers are emitted by emitImports, they do not need to be emitted here.
 
-	if obj.Exported() {
+	if obj.Exported() || (i.generationOptions.EnableUnexportedMonikers && !isLocalObject(obj)) {
 		i.emitExportMoniker(resultSetID, p, obj)
 	}
 
@@ -733,7 +857,8 @@ func (i *Indexer) indexReferences() {
 
 // indexReferencesForPackage emits data for each reference within the given package.
 func (i *Indexer) indexReferencesForPackage(p *packages.Package) {
-	for ident, definitionObj := range p.TypesInfo.Uses {
+	for _, ident := range identsOf(p.TypesInfo.Uses, i.generationOptions.Deterministic) {
+		definitionObj := p.TypesInfo.Uses[ident]
 		if definitionObj == nil {
 			continue
 		}
@@ -846,11 +971,25 @@ func (i *Indexer) indexReferenceToExternalDefinition(p *packages.Package, docume
 		return findExternalHoverContents(i.packageDataCache, i.packages, p, definitionObj)
 	})
 
-	rangeID, _ := i.ensureRangeFor(pos, definitionObj)
+	rangeID, isNewRange := i.ensureRangeFor(pos, definitionObj)
 	if hoverResultID != 0 {
 		_ = i.emitter.EmitTextDocumentHover(rangeID, hoverResultID)
 	}
 
+	if !isNewRange {
+		// Not a new range result; this occurs when the definition and reference ranges overlap
+		// (e.g., embedded fields of a type declared in a dependency). The range is already linked
+		// to the result set of its definition, so we attach the import moniker directly to the
+		// range instead of linking the range to a second result set.
+		monikerID, ok := i.ensureImportMonikerFor(p, definitionObj)
+		if !ok {
+			return 0, false
+		}
+
+		_ = i.emitter.EmitMonikerEdge(rangeID, monikerID)
+		return rangeID, true
+	}
+
 	// Only emit an import moniker which will link to the external definition. If we actually
 	// put a textDocument/references result here, we would not traverse to lookup the external defintion
 	// via the moniker.
@@ -906,13 +1045,15 @@ func (i *Indexer) linkItemsToDefinitions(d *DefinitionInfo) {
 	_ = i.emitter.EmitTextDocumentReferences(d.ResultSetID, refResultID)
 	_ = i.emitter.EmitItemOfDefinitions(refResultID, []uint64{d.RangeID}, d.DocumentID)
 
-	for documentID, rangeIDs := range d.ReferenceRangeIDs {
-		_ = i.emitter.EmitItemOfReferences(refResultID, rangeIDs, documentID)
+	for _, documentID := range sortedKeys(d.ReferenceRangeIDs) {
+		_ = i.emitter.EmitItemOfReferences(refResultID, d.ReferenceRangeIDs[documentID], documentID)
 	}
 }
 
 func (i *Indexer) linkImportMonikersToRanges() {
-	for monikerID, documentReferences := range i.importMonikerReferences {
+	for _, monikerID := range sortedKeys(i.importMonikerReferences) {
+		documentReferences := i.importMonikerReferences[monikerID]
+
 		// emit one result set and reference result per monikerID
 		resultSetID := i.emitter.EmitResultSet()
 		referenceResultID := i.emitter.EmitReferenceResult()
@@ -921,11 +1062,9 @@ func (i *Indexer) linkImportMonikersToRanges() {
 		_ = i.emitter.EmitMonikerEdge(resultSetID, monikerID)
 
 		// Link the ranges correctly to the result
-		for documentID, rangeSet := range documentReferences {
-			rangeIDs := make([]uint64, 0, len(rangeSet))
-			for rangeID := range rangeSet {
-				rangeIDs = append(rangeIDs, rangeID)
-
+		for _, documentID := range sortedKeys(documentReferences) {
+			rangeIDs := sortedKeys(documentReferences[documentID])
+			for _, rangeID := range rangeIDs {
 				_ = i.emitter.EmitNext(rangeID, resultSetID)
 			}
 
@@ -953,14 +1092,31 @@ func (i *Indexer) linkContainsForDocument(d *DocumentInfo) {
 
 // linkContainsForProject emits a contains edge between the target project and all indexed documents.
 func (i *Indexer) linkContainsForProject() {
-	documentIDs := make([]uint64, 0, len(i.documents))
-	for _, info := range i.documents {
-		documentIDs = append(documentIDs, info.DocumentID)
+	documentIDsByProject := map[uint64][]uint64{}
+	for filename, info := range i.documents {
+		projectID := i.projectIDForFile(filename)
+		documentIDsByProject[projectID] = append(documentIDsByProject[projectID], info.DocumentID)
+	}
+
+	for _, projectID := range sortedKeys(documentIDsByProject) {
+		documentIDs := documentIDsByProject[projectID]
+		sort.Slice(documentIDs, func(i, j int) bool { return documentIDs[i] < documentIDs[j] })
+
+		_ = i.emitter.EmitContains(projectID, documentIDs)
 	}
+}
 
-	if len(documentIDs) > 0 {
-		_ = i.emitter.EmitContains(i.projectID, documentIDs)
+// projectIDForFile returns the identifier of the project vertex containing the given file. In
+// workspace mode, this is the project of the innermost workspace module containing the file.
+func (i *Indexer) projectIDForFile(filename string) uint64 {
+	projectID, dir := i.projectID, ""
+	for j, module := range i.generationOptions.WorkspaceModules {
+		if strings.HasPrefix(filename, module.Dir+string(filepath.Separator)) && len(module.Dir) > len(dir) {
+			projectID, dir = i.workspaceProjectIDs[j], module.Dir
+		}
 	}
+
+	return projectID
 }
 
 func (i *Indexer) indexPackageDeclarations() {
@@ -1,16 +1,24 @@
 package indexer
 
 import (
+	"bytes"
+	"io"
 	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hexops/autogold"
+	"github.com/sourcegraph/lsif-go/internal/compression"
+	"github.com/sourcegraph/lsif-go/internal/diff"
+	"github.com/sourcegraph/lsif-go/internal/dump"
 	"github.com/sourcegraph/lsif-go/internal/gomod"
 	"github.com/sourcegraph/lsif-go/internal/output"
+	"github.com/sourcegraph/lsif-go/internal/validation"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
 )
 
 var dependencies = map[string]gomod.GoModule{
@@ -37,7 +45,7 @@ func TestIndexer(t *testing.T) {
 		"0.0.1",
 		dependencies,
 		projectDependencies,
-		w,
+		NewJSONEmitter(w),
 		NewPackageDataCache(),
 		output.Options{},
 		NewGenerationOptions(),
@@ -47,6 +55,12 @@ func TestIndexer(t *testing.T) {
 		t.Fatalf("unexpected error indexing testdata: %s", err.Error())
 	}
 
+	t.Run("produces a valid graph", func(t *testing.T) {
+		for _, err := range validation.Validate(w.elements) {
+			t.Error(err)
+		}
+	})
+
 	t.Run("check Parallel function hover text", func(t *testing.T) {
 		r := mustRange(t, w, "file://"+filepath.Join(projectRoot, "parallel.go"), 13, 5)
 
@@ -613,6 +627,137 @@ func TestIndexer(t *testing.T) {
 }
 
 
+func TestIndexer_compressedOutput(t *testing.T) {
+	generationOptions := NewGenerationOptions()
+	generationOptions.Deterministic = true
+
+	var expected bytes.Buffer
+	indexFixtures(t, writer.NewJSONWriter(&expected), generationOptions)
+
+	for _, algorithm := range []compression.Algorithm{compression.Gzip, compression.Zstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			var buf bytes.Buffer
+			compressedOut, err := compression.NewWriter(&buf, algorithm)
+			if err != nil {
+				t.Fatalf("unexpected error creating compressor: %s", err)
+			}
+
+			indexFixtures(t, writer.NewJSONWriter(compressedOut), generationOptions)
+			if err := compressedOut.Close(); err != nil {
+				t.Fatalf("unexpected error closing compressor: %s", err)
+			}
+
+			r, err := compression.NewReader(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error creating decompressor: %s", err)
+			}
+			defer r.Close()
+
+			decompressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("unexpected error decompressing dump: %s", err)
+			}
+
+			compareDumps(t, expected.Bytes(), decompressed)
+		})
+	}
+}
+
+func TestIndexer_deterministic(t *testing.T) {
+	index := func() []byte {
+		generationOptions := NewGenerationOptions()
+		generationOptions.Deterministic = true
+
+		var buf bytes.Buffer
+		indexFixtures(t, writer.NewJSONWriter(&buf), generationOptions)
+		return buf.Bytes()
+	}
+
+	first := index()
+	second := index()
+
+	if len(first) == 0 {
+		t.Fatalf("expected non-empty dump")
+	}
+
+	compareDumps(t, first, second)
+}
+
+func TestIndexer_incremental(t *testing.T) {
+	projectRoot := path.Join(getRepositoryRoot(t), "fixtures")
+
+	index := func(previous *PreviousDump) (*Indexer, []interface{}) {
+		generationOptions := NewGenerationOptions()
+		generationOptions.Deterministic = true
+		generationOptions.EnableCallHierarchy = true
+		generationOptions.Previous = previous
+
+		var buf bytes.Buffer
+		indexer := indexFixtures(t, writer.NewJSONWriter(&buf), generationOptions)
+
+		elements, err := dump.Read(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error reading dump: %s", err)
+		}
+
+		return indexer, elements
+	}
+
+	_, elements := index(nil)
+
+	calls := renderCalls(elements)
+	if len(calls) == 0 {
+		t.Fatalf("expected call hierarchy edges in full dump")
+	}
+
+	testCases := []struct {
+		name         string
+		changedFiles []string
+		reused       func(n int) bool
+	}{
+		{name: "no changes", changedFiles: nil, reused: func(n int) bool { return n > 0 }},
+		{name: "changed file", changedFiles: []string{filepath.Join(projectRoot, "internal", "secret", "doc.go")}, reused: func(n int) bool { return n > 0 }},
+		{name: "changed go.mod", changedFiles: []string{filepath.Join(projectRoot, "go.mod")}, reused: func(n int) bool { return n == 0 }},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			indexer, incrementalElements := index(&PreviousDump{Elements: elements, ChangedFiles: testCase.changedFiles})
+
+			if n := len(indexer.reused); !testCase.reused(n) {
+				t.Errorf("unexpected number of reused packages: %d", n)
+			}
+			for p := range indexer.reused {
+				for _, filename := range p.GoFiles {
+					for _, changedFile := range testCase.changedFiles {
+						if filepath.Dir(filename) == filepath.Dir(changedFile) {
+							t.Errorf("package %s was reused despite changes to %s", p.ID, changedFile)
+						}
+					}
+				}
+			}
+
+			for _, err := range validation.Validate(incrementalElements) {
+				t.Error(err)
+			}
+
+			changes, err := diff.Diff(dump.NewGraph(elements), dump.NewGraph(incrementalElements))
+			if err != nil {
+				t.Fatalf("unexpected error comparing dumps: %s", err)
+			}
+			if len(changes) != 0 {
+				var buf bytes.Buffer
+				diff.Write(changes, &buf)
+				t.Errorf("incremental dump differs from full dump:\n%s", buf.String())
+			}
+
+			if diff := cmp.Diff(calls, renderCalls(incrementalElements)); diff != "" {
+				t.Errorf("incremental call hierarchy differs from full dump (-full +incremental):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestIndexer_shouldVisitPackage(t *testing.T) {
 	w := &capturingWriter{}
 	projectRoot := path.Join(getRepositoryRoot(t), "fixtures")
@@ -625,7 +770,7 @@ func TestIndexer_shouldVisitPackage(t *testing.T) {
 		"0.0.1",
 		dependencies,
 		projectDependencies,
-		w,
+		NewJSONEmitter(w),
 		NewPackageDataCache(),
 		output.Options{},
 		NewGenerationOptions(),
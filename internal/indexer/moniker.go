@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -13,12 +14,14 @@ import (
 // identifier (either a range or a result set identifier). This will also emit links between
 // the moniker vertex and the package information vertex representing the current module.
 func (i *Indexer) emitExportMoniker(sourceID uint64, p *packages.Package, obj ObjectLike) {
-	if i.moduleName == "" {
+	packageName := makeMonikerPackage(obj)
+
+	moduleName := i.moduleNameFor(packageName)
+	if moduleName == "" {
 		// Unknown dependencies, skip export monikers
 		return
 	}
 
-	packageName := makeMonikerPackage(obj)
 	if strings.HasPrefix(packageName, "_"+i.projectRoot) {
 		packageName = i.repositoryRemote + strings.TrimSuffix(packageName[len(i.projectRoot)+1:], "_test")
 	}
@@ -29,14 +32,47 @@ func (i *Indexer) emitExportMoniker(sourceID uint64, p *packages.Package, obj Ob
 		makeMonikerIdentifier(i.packageDataCache, p, obj),
 	))
 
+	i.emitMonikerSymbolKind(monikerID, symbolKindOf(obj))
+
 	// Lazily emit package information vertex and attach it to moniker
-	packageInformationID := i.ensurePackageInformation(i.moduleName, i.moduleVersion)
+	packageInformationID := i.ensurePackageInformation(moduleName, i.moduleVersion)
 	_ = i.emitter.EmitPackageInformationEdge(monikerID, packageInformationID)
 
 	// Attach moniker to source element
 	_ = i.emitter.EmitMonikerEdge(sourceID, monikerID)
 }
 
+// moduleNameFor returns the resolved name of the module containing the given package. In
+// workspace mode, this is the innermost workspace module whose path is a prefix of the
+// package path.
+func (i *Indexer) moduleNameFor(pkg string) string {
+	for _, prefix := range packagePrefixes(pkg) {
+		for _, module := range i.generationOptions.WorkspaceModules {
+			if module.Path == prefix {
+				return module.Name
+			}
+		}
+	}
+
+	return i.moduleName
+}
+
+// isProjectModule returns true if the given resolved module name is the name of the module
+// being indexed or of one of the modules of the workspace being indexed.
+func (i *Indexer) isProjectModule(name string) bool {
+	if name == i.moduleName {
+		return true
+	}
+
+	for _, module := range i.generationOptions.WorkspaceModules {
+		if module.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // joinMonikerParts joins the non-empty strings in the given list by a colon.
 func joinMonikerParts(parts ...string) string {
 	nonEmpty := parts[:0]
@@ -54,25 +90,56 @@ func joinMonikerParts(parts ...string) string {
 // the moniker vertex and the package information vertex representing the dependency containing
 // the identifier.
 func (i *Indexer) emitImportMoniker(rangeID uint64, p *packages.Package, obj ObjectLike, document *DocumentInfo) bool {
+	monikerID, ok := i.ensureImportMonikerFor(p, obj)
+	if !ok {
+		return false
+	}
+
+	// Monikers will be linked during Indexer.linkImportMonikersToRanges
+	i.addImportMonikerReference(monikerID, rangeID, document.DocumentID)
+	return true
+}
+
+// ensureImportMonikerFor returns the identifier of the import moniker of the given object,
+// which is defined in a dependency. The moniker and the package information vertex of the
+// dependency are emitted if they have not yet been emitted. This method returns false if
+// the object does not belong to a known dependency.
+func (i *Indexer) ensureImportMonikerFor(p *packages.Package, obj ObjectLike) (uint64, bool) {
 	pkg := makeMonikerPackage(obj)
-	monikerIdentifier := joinMonikerParts(pkg, makeMonikerIdentifier(i.packageDataCache, p, obj))
+	return i.ensureImportMonikerForIdentifier(pkg, joinMonikerParts(pkg, makeMonikerIdentifier(i.packageDataCache, p, obj)), symbolKindOf(obj))
+}
 
+// ensureImportMonikerForIdentifier returns the identifier of the import moniker with the given
+// identifier for an object of the given package and kind, as ensureImportMonikerFor.
+func (i *Indexer) ensureImportMonikerForIdentifier(pkg, monikerIdentifier string, kind lsif.SymbolKind) (uint64, bool) {
 	for _, moduleName := range packagePrefixes(pkg) {
 		if module, ok := i.dependencies[moduleName]; ok {
 			// Lazily emit package information vertex
 			packageInformationID := i.ensurePackageInformation(module.Name, module.Version)
 
 			// Lazily emit moniker vertex
-			monikerID := i.ensureImportMoniker(monikerIdentifier, packageInformationID)
+			return i.ensureImportMoniker(monikerIdentifier, packageInformationID, kind), true
+		}
+	}
+
+	return 0, false
+}
 
-			// Monikers will be linked during Indexer.linkImportMonikersToRanges
-			i.addImportMonikerReference(monikerID, rangeID, document.DocumentID)
+// ensureMonikerResultSet returns the identifier of a result set linked to the given import moniker,
+// which stands in for an object defined in a dependency in edges that connect result sets. A result
+// set is emitted only if one for the same moniker has not yet been emitted.
+func (i *Indexer) ensureMonikerResultSet(monikerID uint64) uint64 {
+	i.monikerResultSetIDsMutex.Lock()
+	defer i.monikerResultSetIDsMutex.Unlock()
 
-			return true
-		}
+	if resultSetID, ok := i.monikerResultSetIDs[monikerID]; ok {
+		return resultSetID
 	}
 
-	return false
+	resultSetID := i.emitter.EmitResultSet()
+	_ = i.emitter.EmitMonikerEdge(resultSetID, monikerID)
+	i.monikerResultSetIDs[monikerID] = resultSetID
+	return resultSetID
 }
 
 // emitImplementationMoniker emits an implementation moniker for the given object linked to the given source
@@ -137,7 +204,7 @@ func (i *Indexer) ensurePackageInformation(name, version string) uint64 {
 // ensureImportMoniker returns the identifier of a moniker vertex with the give identifier
 // attached to the given package information identifier. A vertex will be emitted only if
 // one with the same key has not yet been emitted.
-func (i *Indexer) ensureImportMoniker(identifier string, packageInformationID uint64) uint64 {
+func (i *Indexer) ensureImportMoniker(identifier string, packageInformationID uint64, kind lsif.SymbolKind) uint64 {
 	key := fmt.Sprintf("%s:%d", identifier, packageInformationID)
 
 	i.importMonikerIDsMutex.RLock()
@@ -156,6 +223,7 @@ func (i *Indexer) ensureImportMoniker(identifier string, packageInformationID ui
 
 	monikerID = i.emitter.EmitMoniker("import", "gomod", identifier)
 	_ = i.emitter.EmitPackageInformationEdge(monikerID, packageInformationID)
+	i.emitMonikerSymbolKind(monikerID, kind)
 	i.importMonikerIDs[key] = monikerID
 	return monikerID
 }
@@ -179,6 +247,47 @@ func (i *Indexer) ensureImplementationMoniker(identifier string, packageInformat
 	return monikerID
 }
 
+// emitMonikerSymbolKind records the kind of the object named by the given moniker, if requested.
+func (i *Indexer) emitMonikerSymbolKind(monikerID uint64, kind lsif.SymbolKind) {
+	if i.generationOptions.EnableMonikerSymbolKinds && kind != 0 {
+		_ = i.emitter.EmitMonikerSymbolKind(monikerID, kind)
+	}
+}
+
+// symbolKindOf returns the kind of symbol of the given object, or zero for packages.
+func symbolKindOf(obj ObjectLike) lsif.SymbolKind {
+	switch v := obj.(type) {
+	case *types.TypeName:
+		switch v.Type().Underlying().(type) {
+		case *types.Struct:
+			return lsif.SymbolKindStruct
+		case *types.Interface:
+			return lsif.SymbolKindInterface
+		}
+
+		return lsif.SymbolKindClass
+
+	case *types.Func:
+		if signature, ok := v.Type().(*types.Signature); ok && signature.Recv() != nil {
+			return lsif.SymbolKindMethod
+		}
+
+		return lsif.SymbolKindFunction
+
+	case *types.Var:
+		if v.IsField() {
+			return lsif.SymbolKindField
+		}
+
+		return lsif.SymbolKindVariable
+
+	case *types.Const:
+		return lsif.SymbolKindConstant
+	}
+
+	return 0
+}
+
 // makeMonikerPackage returns the package prefix used to construct a unique moniker for the given object.
 // A full moniker has the form `{package prefix}:{identifier suffix}`.
 func makeMonikerPackage(obj ObjectLike) string {
@@ -232,6 +341,32 @@ func makeMonikerIdentifier(packageDataCache *PackageDataCache, p *packages.Packa
 	return obj.Name()
 }
 
+// isLocalObject returns true if the given object is only visible within the file or function
+// that declares it (e.g. function parameters, local variables, labels, and named imports).
+// Such objects cannot be referenced from another document and so do not need a moniker.
+func isLocalObject(obj ObjectLike) bool {
+	switch v := obj.(type) {
+	case *types.Label, *types.PkgName:
+		return true
+
+	case *types.Func:
+		// Functions and methods are always declared at the package level
+		return false
+
+	case *types.Var:
+		if v.IsField() {
+			return false
+		}
+	}
+
+	typesObj, ok := obj.(types.Object)
+	if !ok || typesObj.Pkg() == nil {
+		return false
+	}
+
+	return typesObj.Parent() != typesObj.Pkg().Scope()
+}
+
 // pkgPath can be used to always return a string for the obj.Pkg().Path()
 //
 // At this time, I am only aware of objects in the Universe scope that do not
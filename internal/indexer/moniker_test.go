@@ -9,8 +9,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
-	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
 )
 
 func TestEmitExportMoniker(t *testing.T) {
@@ -22,7 +22,7 @@ func TestEmitExportMoniker(t *testing.T) {
 		projectRoot:             "/users/efritz/dev/sourcegraph/lsif-go",
 		moduleName:              "https://github.com/sourcegraph/lsif-go",
 		moduleVersion:           "3.14.159",
-		emitter:                 writer.NewEmitter(w),
+		emitter:                 NewJSONEmitter(w),
 		importMonikerIDs:        map[string]uint64{},
 		packageInformationIDs:   map[string]uint64{},
 		importMonikerReferences: map[uint64]map[uint64]map[uint64]setVal{},
@@ -74,7 +74,7 @@ func TestEmitExportMonikerPreGoMod(t *testing.T) {
 		projectRoot:             "/users/efritz/dev/sourcegraph/lsif-go",
 		moduleName:              "https://github.com/sourcegraph/lsif-go",
 		moduleVersion:           "3.14.159",
-		emitter:                 writer.NewEmitter(w),
+		emitter:                 NewJSONEmitter(w),
 		importMonikerIDs:        map[string]uint64{},
 		packageInformationIDs:   map[string]uint64{},
 		importMonikerReferences: map[uint64]map[uint64]map[uint64]setVal{},
@@ -117,6 +117,74 @@ func TestEmitExportMonikerPreGoMod(t *testing.T) {
 	}
 }
 
+func TestEmitExportMonikerWorkspace(t *testing.T) {
+	w := &capturingWriter{}
+
+	generationOptions := NewGenerationOptions()
+	generationOptions.WorkspaceModules = []gomod.WorkspaceModule{
+		{Path: "github.com/test/repo", Name: "https://github.com/test/repo", Dir: "/dev/repo"},
+		{Path: "github.com/test/repo/lib", Name: "https://github.com/test/repo/lib", Dir: "/dev/repo/lib"},
+	}
+
+	indexer := &Indexer{
+		repositoryRemote:        "github.com/test/repo",
+		repositoryRoot:          "/dev/repo",
+		projectRoot:             "/dev/repo",
+		moduleVersion:           "3.14.159",
+		emitter:                 NewJSONEmitter(w),
+		importMonikerIDs:        map[string]uint64{},
+		packageInformationIDs:   map[string]uint64{},
+		importMonikerReferences: map[uint64]map[uint64]map[uint64]setVal{},
+		stripedMutex:            newStripedMutex(),
+		generationOptions:       generationOptions,
+	}
+
+	testCases := []struct {
+		pkg      string
+		expected string
+	}{
+		{pkg: "github.com/test/repo/cmd", expected: "https://github.com/test/repo"},
+		{pkg: "github.com/test/repo/lib", expected: "https://github.com/test/repo/lib"},
+		{pkg: "github.com/test/repo/lib/internal", expected: "https://github.com/test/repo/lib"},
+	}
+
+	for j, testCase := range testCases {
+		object := types.NewConst(
+			token.Pos(42),
+			types.NewPackage(testCase.pkg, "pkg"),
+			"foobar",
+			&types.Basic{},
+			constant.MakeBool(true),
+		)
+
+		sourceID := uint64(100 + j)
+		indexer.emitExportMoniker(sourceID, nil, object)
+
+		monikers := findMonikersByRangeOrReferenceResultID(w, sourceID)
+		if len(monikers) < 1 {
+			t.Fatalf("could not find moniker for %s", testCase.pkg)
+		}
+
+		packageInformation := findPackageInformationByMonikerID(w, monikers[0].ID)
+		if len(packageInformation) < 1 {
+			t.Fatalf("could not find package information for %s", testCase.pkg)
+		}
+		if packageInformation[0].Name != testCase.expected {
+			t.Errorf("incorrect package information name for %s. want=%q have=%q", testCase.pkg, testCase.expected, packageInformation[0].Name)
+		}
+	}
+
+	t.Run("unknown module", func(t *testing.T) {
+		object := types.NewConst(token.Pos(42), types.NewPackage("github.com/other/repo", "pkg"), "foobar", &types.Basic{}, constant.MakeBool(true))
+
+		indexer.emitExportMoniker(200, nil, object)
+
+		if monikers := findMonikersByRangeOrReferenceResultID(w, 200); len(monikers) != 0 {
+			t.Errorf("unexpected monikers for package outside of the workspace: %v", monikers)
+		}
+	})
+}
+
 func TestEmitImportMoniker(t *testing.T) {
 	w := &capturingWriter{}
 
@@ -124,7 +192,7 @@ func TestEmitImportMoniker(t *testing.T) {
 		dependencies: map[string]gomod.GoModule{
 			"github.com/test/pkg/sub1": {Name: "github.com/test/pkg/sub1", Version: "1.2.3-deadbeef"},
 		},
-		emitter:                 writer.NewEmitter(w),
+		emitter:                 NewJSONEmitter(w),
 		importMonikerIDs:        map[string]uint64{},
 		packageInformationIDs:   map[string]uint64{},
 		stripedMutex:            newStripedMutex(),
@@ -174,6 +242,67 @@ func TestEmitImportMoniker(t *testing.T) {
 	}
 }
 
+func TestEmitImportMonikerSymbolKind(t *testing.T) {
+	w := &capturingWriter{}
+
+	indexer := &Indexer{
+		dependencies: map[string]gomod.GoModule{
+			"github.com/test/pkg": {Name: "github.com/test/pkg", Version: "1.2.3"},
+		},
+		emitter:               NewJSONEmitter(w),
+		importMonikerIDs:      map[string]uint64{},
+		packageInformationIDs: map[string]uint64{},
+		generationOptions:     GenerationOptions{EnableMonikerSymbolKinds: true},
+	}
+
+	object := types.NewFunc(token.Pos(42), types.NewPackage("github.com/test/pkg", "pkg"), "Run", types.NewSignatureType(nil, nil, nil, nil, nil, false))
+
+	monikerID, ok := indexer.ensureImportMonikerFor(nil, object)
+	if !ok {
+		t.Fatalf("failed to emit import moniker")
+	}
+
+	var kinds []lsif.MonikerSymbolKind
+	for _, element := range w.elements {
+		if e, ok := element.(lsif.MonikerSymbolKind); ok {
+			kinds = append(kinds, e)
+		}
+	}
+	if len(kinds) != 1 || kinds[0].Moniker != monikerID || kinds[0].Kind != lsif.SymbolKindFunction {
+		t.Errorf("unexpected moniker symbol kinds. want=[{Moniker:%d Kind:%d}] have=%+v", monikerID, lsif.SymbolKindFunction, kinds)
+	}
+}
+
+func TestSymbolKindOf(t *testing.T) {
+	pkg := types.NewPackage("github.com/test/pkg", "pkg")
+	structType := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "S", nil), types.NewStruct(nil, nil), nil)
+	interfaceType := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "I", nil), types.NewInterfaceType(nil, nil), nil)
+	basicType := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "B", nil), types.Typ[types.Int], nil)
+	method := types.NewFunc(token.NoPos, pkg, "M", types.NewSignatureType(types.NewVar(token.NoPos, pkg, "s", structType), nil, nil, nil, nil, false))
+	function := types.NewFunc(token.NoPos, pkg, "F", types.NewSignatureType(nil, nil, nil, nil, nil, false))
+
+	testCases := []struct {
+		obj      ObjectLike
+		expected lsif.SymbolKind
+	}{
+		{structType.Obj(), lsif.SymbolKindStruct},
+		{interfaceType.Obj(), lsif.SymbolKindInterface},
+		{basicType.Obj(), lsif.SymbolKindClass},
+		{method, lsif.SymbolKindMethod},
+		{function, lsif.SymbolKindFunction},
+		{types.NewField(token.NoPos, pkg, "X", types.Typ[types.Int], false), lsif.SymbolKindField},
+		{types.NewVar(token.NoPos, pkg, "v", types.Typ[types.Int]), lsif.SymbolKindVariable},
+		{types.NewConst(token.NoPos, pkg, "c", types.Typ[types.Int], constant.MakeInt64(1)), lsif.SymbolKindConstant},
+		{types.NewPkgName(token.NoPos, pkg, "pkg", pkg), 0},
+	}
+
+	for _, testCase := range testCases {
+		if kind := symbolKindOf(testCase.obj); kind != testCase.expected {
+			t.Errorf("unexpected symbol kind of %s. want=%d have=%d", testCase.obj.Name(), testCase.expected, kind)
+		}
+	}
+}
+
 func TestPackagePrefixes(t *testing.T) {
 	expectedPackages := []string{
 		"github.com/foo/bar/baz/bonk/internal/secrets",
@@ -252,3 +381,22 @@ func TestJoinMonikerParts(t *testing.T) {
 		}
 	}
 }
+
+func TestIsLocalObject(t *testing.T) {
+	packages := getTestPackages(t)
+
+	testCases := map[string]bool{
+		"ParallelizableFunc": false, // package-level type
+		"Parallel":           false, // package-level function
+		"wg":                 true,  // local variable
+		"NestedB":            false, // struct field
+	}
+
+	for name, expected := range testCases {
+		_, obj := findDefinitionByName(t, packages, name)
+
+		if local := isLocalObject(obj); local != expected {
+			t.Errorf("unexpected result for %q. want=%v have=%v", name, expected, local)
+		}
+	}
+}
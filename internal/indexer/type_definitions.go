@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typeDefinitionResults caches the type definition result of each type defined in the project.
+type typeDefinitionResults struct {
+	m   sync.Mutex
+	ids map[*DefinitionInfo]uint64
+}
+
+// indexTypeDefinitions emits a type definition result for each variable, struct field, parameter,
+// and named result whose type is a named type. The result set of the definition is linked to the
+// definition of its type, so that every reference of the variable shares the type definition. This
+// includes the definitions copied from a previous dump, whose type definitions are not copied.
+func (i *Indexer) indexTypeDefinitions() {
+	i.typeDefinitionResults = &typeDefinitionResults{ids: map[*DefinitionInfo]uint64{}}
+	i.visitEachLoadedPackage("Indexing type definitions", i.indexTypeDefinitionsForPackage)
+}
+
+// indexTypeDefinitionsForPackage emits the type definitions of the variables defined in the given package.
+func (i *Indexer) indexTypeDefinitionsForPackage(p *packages.Package) {
+	for _, ident := range identsOf(p.TypesInfo.Defs, i.generationOptions.Deterministic) {
+		v, ok := p.TypesInfo.Defs[ident].(*types.Var)
+		if !ok {
+			continue
+		}
+
+		// Each definition is visited only from the package that indexed it
+		if _, document, ok := i.positionAndDocument(p, v.Pos()); !ok || document == nil {
+			continue
+		}
+
+		d := i.getDefinitionInfo(v, ident)
+		if d == nil {
+			continue
+		}
+
+		typeName := namedTypeOf(v.Type())
+		if typeName == nil {
+			continue
+		}
+
+		if typeDefinitionResultID, ok := i.ensureTypeDefinitionResult(typeName); ok {
+			_ = i.emitter.EmitTextDocumentTypeDefinition(d.ResultSetID, typeDefinitionResultID)
+		}
+	}
+}
+
+// ensureTypeDefinitionResult returns the identifier of the type definition result of the given type,
+// emitting it if it has not yet been emitted. The result has an item edge to the range of the definition
+// of the type. Types defined in a dependency have no type definition result, as the range of their
+// definition is not part of the dump, and a type definition result cannot be linked to the result set
+// of their import moniker. This method returns false for such types.
+func (i *Indexer) ensureTypeDefinitionResult(typeName *types.TypeName) (uint64, bool) {
+	d := i.getDefinitionInfo(typeName, &ast.Ident{Name: typeName.Name()})
+	if d == nil {
+		return 0, false
+	}
+
+	results := i.typeDefinitionResults
+	results.m.Lock()
+	defer results.m.Unlock()
+
+	if typeDefinitionResultID, ok := results.ids[d]; ok {
+		return typeDefinitionResultID, true
+	}
+
+	typeDefinitionResultID := i.emitter.EmitTypeDefinitionResult()
+	_ = i.emitter.EmitItem(typeDefinitionResultID, []uint64{d.RangeID}, d.DocumentID)
+
+	results.ids[d] = typeDefinitionResultID
+	return typeDefinitionResultID, true
+}
+
+// namedTypeOf returns the declaration of the named type underlying the given type, unwrapping pointers,
+// slices, arrays, channels, and maps (to the type of their values). Instantiations of a generic type
+// resolve to the generic type. Predeclared types, such as error, and type parameters have no declaration
+// that can be linked to, and nil is returned for them and for other unnamed types.
+func namedTypeOf(t types.Type) *types.TypeName {
+	for {
+		switch v := t.(type) {
+		case *types.Pointer:
+			t = v.Elem()
+		case *types.Slice:
+			t = v.Elem()
+		case *types.Array:
+			t = v.Elem()
+		case *types.Chan:
+			t = v.Elem()
+		case *types.Map:
+			t = v.Elem()
+
+		case *types.Named:
+			if obj := v.Origin().Obj(); obj.Pkg() != nil {
+				return obj
+			}
+			return nil
+
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestIndexTypeDefinitions(t *testing.T) {
+	w := &capturingWriter{
+		ranges:    map[uint64]protocol.Range{},
+		documents: map[uint64]protocol.Document{},
+		contains:  map[uint64]uint64{},
+	}
+	indexFixtures(t, w, NewGenerationOptions())
+
+	hierarchies := "file://" + filepath.Join(getRepositoryRoot(t), "fixtures", "hierarchies.go")
+	typeDefinitions := "file://" + filepath.Join(getRepositoryRoot(t), "fixtures", "type_definitions.go")
+	resultSetAt := func(uri string, line, character int) uint64 {
+		resultSetID, ok := findResultSetByRangeID(w, mustRange(t, w, uri, line, character).ID)
+		if !ok {
+			t.Fatalf("no result set for range at %d:%d", line, character)
+		}
+		return resultSetID
+	}
+
+	t.Run("variables link to the definition of their type", func(t *testing.T) {
+		assertRanges(t, w, typeDefinitionRanges(w, resultSetAt(hierarchies, 36, 4)), []string{"hierarchies.go:25:5-25:9"}, "DefaultTile type definition")
+	})
+
+	t.Run("parameters link to the definition of their type", func(t *testing.T) {
+		assertRanges(t, w, typeDefinitionRanges(w, resultSetAt(hierarchies, 31, 13)), []string{"hierarchies.go:10:5-10:12"}, "p type definition")
+	})
+
+	t.Run("maps, slices, and pointers are unwrapped", func(t *testing.T) {
+		assertRanges(t, w, typeDefinitionRanges(w, resultSetAt(typeDefinitions, 5, 4)), []string{"hierarchies.go:25:5-25:9"}, "Board type definition")
+	})
+
+	t.Run("types of dependencies have no type definition", func(t *testing.T) {
+		resultSetID := resultSetAt(typeDefinitions, 8, 4)
+		for _, elem := range w.elements {
+			if e, ok := elem.(lsif.TextDocumentTypeDefinition); ok && e.OutV == resultSetID {
+				t.Errorf("unexpected type definition of Texture")
+			}
+		}
+	})
+
+	t.Run("next edges link ranges and result sets to result sets", func(t *testing.T) {
+		resultSets := map[uint64]struct{}{}
+		for _, elem := range w.elements {
+			if e, ok := elem.(protocol.ResultSet); ok {
+				resultSets[e.ID] = struct{}{}
+			}
+		}
+
+		for _, elem := range w.elements {
+			if e, ok := elem.(protocol.Next); ok {
+				_, fromRange := w.ranges[e.OutV]
+				_, fromResultSet := resultSets[e.OutV]
+				if !fromRange && !fromResultSet {
+					t.Errorf("next edge %d starts at vertex %d, which is neither a range nor a result set", e.ID, e.OutV)
+				}
+				if _, ok := resultSets[e.InV]; !ok {
+					t.Errorf("next edge %d ends at vertex %d, which is not a result set", e.ID, e.InV)
+				}
+			}
+		}
+	})
+}
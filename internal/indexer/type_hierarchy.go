@@ -0,0 +1,224 @@
+package indexer
+
+import (
+	"go/ast"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typeHierarchy tracks the types defined in dependencies whose supertypes have been emitted.
+type typeHierarchy struct {
+	m       sync.Mutex
+	visited map[uint64]struct{} // monikerID
+}
+
+// indexTypeHierarchy emits a supertype edge from the result set of each struct type to the result set
+// of each type embedded as one of its fields, and from the result set of each interface to the result
+// set of each interface that it embeds. Types defined in a dependency are represented by a result set
+// linked to their import moniker, and the types that they embed are followed transitively so that a
+// chain of embeddings can be navigated after it leaves the project. This includes the types copied
+// from a previous dump, whose type hierarchy is not copied.
+func (i *Indexer) indexTypeHierarchy() {
+	i.typeHierarchy = &typeHierarchy{visited: map[uint64]struct{}{}}
+	i.visitEachLoadedPackage("Indexing type hierarchy", i.indexTypeHierarchyForPackage)
+}
+
+// indexTypeHierarchyForPackage emits the supertypes of the types declared in the given package.
+func (i *Indexer) indexTypeHierarchyForPackage(p *packages.Package) {
+	for _, f := range p.Syntax {
+		// Inspect the entire file, as types declared within functions may also embed types
+		ast.Inspect(f, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				i.indexSupertypes(p, spec)
+			}
+
+			return true
+		})
+	}
+}
+
+// indexSupertypes emits the supertypes of the type declared by the given type spec.
+func (i *Indexer) indexSupertypes(p *packages.Package, spec *ast.TypeSpec) {
+	if spec.Assign.IsValid() {
+		// Aliases share the supertypes of the aliased type
+		return
+	}
+
+	_, document, ok := i.positionAndDocument(p, spec.Name.Pos())
+	if !ok || document == nil {
+		return
+	}
+
+	obj := p.TypesInfo.Defs[spec.Name]
+	if obj == nil {
+		return
+	}
+	d := i.getDefinitionInfo(obj, spec.Name)
+	if d == nil {
+		return
+	}
+
+	for _, expr := range embeddedTypeExprs(spec.Type) {
+		typeName := embeddedTypeName(p.TypesInfo.TypeOf(expr))
+		if typeName == nil {
+			continue
+		}
+
+		if resultSetID, ok := i.ensureSupertypeResultSet(p, typeName); ok {
+			_ = i.emitter.EmitTypeHierarchySupertype(d.ResultSetID, resultSetID, i.embeddingRange(p, expr), document.DocumentID)
+		}
+	}
+}
+
+// ensureSupertypeResultSet returns the identifier of the result set of the given embedded type. If the
+// type is defined in a dependency, the result set is linked to its import moniker and the supertypes of
+// the type are emitted, unless they have already been emitted. This method returns false if the type
+// cannot be resolved either way.
+func (i *Indexer) ensureSupertypeResultSet(p *packages.Package, typeName *types.TypeName) (uint64, bool) {
+	if d := i.getDefinitionInfo(typeName, &ast.Ident{Name: typeName.Name()}); d != nil {
+		return d.ResultSetID, true
+	}
+
+	monikerID, ok := i.ensureImportMonikerFor(p, typeName)
+	if !ok {
+		return 0, false
+	}
+	resultSetID := i.ensureMonikerResultSet(monikerID)
+
+	if i.typeHierarchy.visit(monikerID) {
+		for _, embedded := range embeddedTypes(typeName.Type().Underlying()) {
+			supertype := embeddedTypeName(embedded)
+			if supertype == nil {
+				continue
+			}
+
+			// The embedding is not part of the project, so it has no range
+			if supertypeResultSetID, ok := i.ensureSupertypeResultSet(p, supertype); ok {
+				_ = i.emitter.EmitTypeHierarchySupertype(resultSetID, supertypeResultSetID, 0, 0)
+			}
+		}
+	}
+
+	return resultSetID, true
+}
+
+// visit marks the type with the given import moniker as visited. This method returns false if the
+// type has already been visited.
+func (h *typeHierarchy) visit(monikerID uint64) bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if _, ok := h.visited[monikerID]; ok {
+		return false
+	}
+
+	h.visited[monikerID] = struct{}{}
+	return true
+}
+
+// embeddingRange returns the identifier of the range of the given embedded type expression. For
+// embedded fields, this is the range of the definition of the field. For embedded interfaces, this
+// is the range of the reference to the interface. This method returns zero if no range was emitted.
+func (i *Indexer) embeddingRange(p *packages.Package, expr ast.Expr) uint64 {
+	ident := typeExprIdent(expr)
+	if ident == nil {
+		return 0
+	}
+
+	if field, ok := p.TypesInfo.Defs[ident].(*types.Var); ok {
+		if d := i.getDefinitionInfo(field, ident); d != nil {
+			return d.RangeID
+		}
+
+		return 0
+	}
+
+	rangeID, _ := i.rangeAt(p.Fset.Position(ident.Pos()))
+	return rangeID
+}
+
+// embeddedTypeExprs returns the type expressions of the embedded fields of the given struct type or
+// the embedded elements of the given interface type.
+func embeddedTypeExprs(expr ast.Expr) []ast.Expr {
+	var fields *ast.FieldList
+	switch v := unparen(expr).(type) {
+	case *ast.StructType:
+		fields = v.Fields
+	case *ast.InterfaceType:
+		fields = v.Methods
+	default:
+		return nil
+	}
+
+	var exprs []ast.Expr
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			exprs = append(exprs, field.Type)
+		}
+	}
+
+	return exprs
+}
+
+// embeddedTypes returns the types of the embedded fields of the given struct type or the embedded
+// elements of the given interface type.
+func embeddedTypes(t types.Type) []types.Type {
+	var embedded []types.Type
+	switch v := t.(type) {
+	case *types.Struct:
+		for j := 0; j < v.NumFields(); j++ {
+			if field := v.Field(j); field.Anonymous() {
+				embedded = append(embedded, field.Type())
+			}
+		}
+
+	case *types.Interface:
+		for j := 0; j < v.NumEmbeddeds(); j++ {
+			embedded = append(embedded, v.EmbeddedType(j))
+		}
+	}
+
+	return embedded
+}
+
+// embeddedTypeName returns the declaration of the given embedded type, which may be a pointer to a
+// named type. Instantiations of a generic type resolve to the generic type. Predeclared types, such
+// as error and comparable, and the unions and approximations of constraint interfaces have no such
+// declaration, and nil is returned for them.
+func embeddedTypeName(t types.Type) *types.TypeName {
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Origin().Obj(); obj.Pkg() != nil {
+			return obj
+		}
+	}
+
+	return nil
+}
+
+// typeExprIdent returns the identifier naming the type of the given type expression, which may be
+// a pointer, a qualified identifier, or an instantiation of a generic type.
+func typeExprIdent(expr ast.Expr) *ast.Ident {
+	expr = unparen(unstar(unparen(expr)))
+
+	switch v := expr.(type) {
+	case *ast.IndexExpr:
+		expr = unparen(v.X)
+	case *ast.IndexListExpr:
+		expr = unparen(v.X)
+	}
+
+	switch v := expr.(type) {
+	case *ast.Ident:
+		return v
+	case *ast.SelectorExpr:
+		return v.Sel
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestIndexTypeHierarchy(t *testing.T) {
+	w := &capturingWriter{
+		ranges:    map[uint64]protocol.Range{},
+		documents: map[uint64]protocol.Document{},
+		contains:  map[uint64]uint64{},
+	}
+
+	indexFixtures(t, w, NewGenerationOptions())
+
+	uri := "file://" + filepath.Join(getRepositoryRoot(t), "fixtures", "hierarchies.go")
+	resultSetAt := func(line, character int) uint64 {
+		resultSetID, ok := findResultSetByRangeID(w, mustRange(t, w, uri, line, character).ID)
+		if !ok {
+			t.Fatalf("no result set for range at %d:%d", line, character)
+		}
+		return resultSetID
+	}
+
+	reader, ok := findMonikerResultSet(w, "github.com/golang/go/std/io:Reader")
+	if !ok {
+		t.Fatalf("no result set for io:Reader")
+	}
+
+	supertypes := map[[3]uint64]int{}
+	for _, elem := range w.elements {
+		if e, ok := elem.(lsif.TypeHierarchySupertype); ok {
+			supertypes[[3]uint64{e.OutV, e.InV, e.Range}]++
+		}
+	}
+
+	for name, expected := range map[string][3]uint64{
+		"Polygon -> Shape":  {resultSetAt(10, 5), resultSetAt(5, 5), mustRange(t, w, uri, 11, 1).ID},
+		"Tile -> Square":    {resultSetAt(25, 5), resultSetAt(16, 5), mustRange(t, w, uri, 26, 1).ID},
+		"Tile -> io.Reader": {resultSetAt(25, 5), reader, mustRange(t, w, uri, 27, 4).ID},
+	} {
+		if count := supertypes[expected]; count != 1 {
+			t.Errorf("unexpected number of supertype edges %s. want=%d have=%d", name, 1, count)
+		}
+	}
+}
@@ -1,6 +1,13 @@
 package indexer
 
-// union concatenates, flattens, and deduplicates the given identifier slices.
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+)
+
+// union concatenates, flattens, and deduplicates the given identifier slices. The
+// resulting identifiers are returned in ascending order.
 func union(as ...[]uint64) (flattened []uint64) {
 	m := map[uint64]struct{}{}
 	for _, a := range as {
@@ -9,9 +16,50 @@ func union(as ...[]uint64) (flattened []uint64) {
 		}
 	}
 
-	for v := range m {
-		flattened = append(flattened, v)
+	return sortedKeys(m)
+}
+
+// sortedKeys returns the keys of the given map in ascending order.
+func sortedKeys[K uint64 | int | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return keys
+}
+
+// identsOf returns the identifiers of the given Defs or Uses map of a package's type info.
+// If ordered is true, the identifiers are returned in source order. Otherwise they are
+// returned in map iteration order.
+func identsOf(m map[*ast.Ident]types.Object, ordered bool) []*ast.Ident {
+	idents := make([]*ast.Ident, 0, len(m))
+	for ident := range m {
+		idents = append(idents, ident)
+	}
+
+	if ordered {
+		sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+	}
+
+	return idents
+}
+
+// caseClausesOf returns the case clauses in the given Implicits map of a package's type info.
+// If ordered is true, the case clauses are returned in source order. Otherwise they are
+// returned in map iteration order.
+func caseClausesOf(m map[ast.Node]types.Object, ordered bool) []ast.Node {
+	var nodes []ast.Node
+	for node := range m {
+		if _, ok := node.(*ast.CaseClause); ok {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if ordered {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Pos() < nodes[j].Pos() })
 	}
 
-	return flattened
+	return nodes
 }
@@ -27,3 +27,11 @@ func TestUnion(t *testing.T) {
 		t.Errorf("unexpected union (-want +got): %s", diff)
 	}
 }
+
+func TestSortedKeys(t *testing.T) {
+	keys := sortedKeys(map[uint64]struct{}{30: {}, 10: {}, 200: {}, 20: {}})
+
+	if diff := cmp.Diff([]uint64{10, 20, 30, 200}, keys); diff != "" {
+		t.Errorf("unexpected keys (-want +got): %s", diff)
+	}
+}
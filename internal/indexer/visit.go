@@ -1,7 +1,9 @@
 package indexer
 
 import (
+	"go/ast"
 	"log"
+	"sort"
 	"sync"
 	"sync/atomic"
 
@@ -47,19 +49,31 @@ func (i *Indexer) visitEachRawFile(name string, fn func(filename string)) {
 	output.WithProgressParallel(&wg, name, i.outputOptions, &count, n)
 }
 
-// visitEachPackage invokes the given visitor function on each indexed package. This method prints the
-// progress of the traversal to stdout asynchronously.
+// visitEachPackage invokes the given visitor function on each indexed package. Packages that are copied
+// from a previous dump are skipped. This method prints the progress of the traversal to stdout asynchronously.
 func (i *Indexer) visitEachPackage(name string, fn func(p *packages.Package)) {
+	i.visitPackages(name, i.indexedPackages(), fn)
+}
+
+// visitEachLoadedPackage invokes the given visitor function on each project package, including packages
+// that are copied from a previous dump. This method prints the progress of the traversal to stdout
+// asynchronously.
+func (i *Indexer) visitEachLoadedPackage(name string, fn func(p *packages.Package)) {
+	i.visitPackages(name, i.packages, fn)
+}
+
+// visitPackages invokes the given visitor function on each of the given packages.
+func (i *Indexer) visitPackages(name string, pkgs []*packages.Package, fn func(p *packages.Package)) {
 	ch := make(chan func())
 
 	go func() {
 		defer close(ch)
 
-		for _, p := range i.packages {
+		for _, p := range pkgs {
 			t := p
 			ch <- func() {
 				if i.outputOptions.Verbosity >= output.VeryVerboseOutput {
-					log.Printf("\tPackage %s", p.ID)
+					log.Printf("\tPackage %s", t.ID)
 				}
 
 				fn(t)
@@ -67,8 +81,8 @@ func (i *Indexer) visitEachPackage(name string, fn func(p *packages.Package)) {
 		}
 	}()
 
-	n := uint64(len(i.packages))
-	wg, count := parallel.Run(ch)
+	n := uint64(len(pkgs))
+	wg, count := i.run(ch)
 	output.WithProgressParallel(wg, name, i.outputOptions, count, n)
 }
 
@@ -89,20 +103,30 @@ func (i *Indexer) visitEachDefinitionInfo(name string, fn func(d *DefinitionInfo
 		n += uint64(len(m))
 	}
 
+	definitionInfos := make([]*DefinitionInfo, 0, n)
+	for _, m := range maps {
+		for _, d := range m {
+			definitionInfos = append(definitionInfos, d)
+		}
+	}
+	if i.generationOptions.Deterministic {
+		sort.Slice(definitionInfos, func(j, k int) bool {
+			return definitionInfos[j].ResultSetID < definitionInfos[k].ResultSetID
+		})
+	}
+
 	ch := make(chan func())
 
 	go func() {
 		defer close(ch)
 
-		for _, m := range maps {
-			for _, d := range m {
-				t := d
-				ch <- func() { fn(t) }
-			}
+		for _, d := range definitionInfos {
+			t := d
+			ch <- func() { fn(t) }
 		}
 	}()
 
-	wg, count := parallel.Run(ch)
+	wg, count := i.run(ch)
 	output.WithProgressParallel(wg, name, i.outputOptions, count, n)
 }
 
@@ -114,13 +138,79 @@ func (i *Indexer) visitEachDocument(name string, fn func(d *DocumentInfo)) {
 	go func() {
 		defer close(ch)
 
-		for _, d := range i.documents {
-			t := d
+		for _, filename := range i.documentFilenames() {
+			t := i.documents[filename]
 			ch <- func() { fn(t) }
 		}
 	}()
 
 	n := uint64(len(i.documents))
-	wg, count := parallel.Run(ch)
+	wg, count := i.run(ch)
 	output.WithProgressParallel(wg, name, i.outputOptions, count, n)
 }
+
+// visitEachDocumentFile invokes the given visitor function on the syntax tree of each document,
+// including the documents of packages copied from a previous dump. Each file is visited once with
+// the package that it belongs to in the packagesByFile map. This method prints the progress of the
+// traversal to stdout asynchronously.
+func (i *Indexer) visitEachDocumentFile(name string, fn func(p *packages.Package, f *ast.File, d *DocumentInfo)) {
+	type documentFile struct {
+		p *packages.Package
+		f *ast.File
+		d *DocumentInfo
+	}
+
+	filesByName := map[string]documentFile{}
+	for _, p := range i.packages {
+		for _, f := range p.Syntax {
+			filename := p.Fset.Position(f.Package).Filename
+			if packages := i.packagesByFile[filename]; len(packages) == 0 || packages[0] != p {
+				continue
+			}
+
+			if d, ok := i.documents[filename]; ok {
+				filesByName[filename] = documentFile{p: p, f: f, d: d}
+			}
+		}
+	}
+
+	ch := make(chan func())
+
+	go func() {
+		defer close(ch)
+
+		for _, filename := range sortedKeys(filesByName) {
+			t := filesByName[filename]
+			ch <- func() { fn(t.p, t.f, t.d) }
+		}
+	}()
+
+	n := uint64(len(filesByName))
+	wg, count := i.run(ch)
+	output.WithProgressParallel(wg, name, i.outputOptions, count, n)
+}
+
+// documentFilenames returns the keys of the documents map. When generating deterministic
+// output, the filenames are returned in sorted order.
+func (i *Indexer) documentFilenames() []string {
+	if i.generationOptions.Deterministic {
+		return sortedKeys(i.documents)
+	}
+
+	filenames := make([]string, 0, len(i.documents))
+	for filename := range i.documents {
+		filenames = append(filenames, filename)
+	}
+
+	return filenames
+}
+
+// run invokes the functions read from the given channel concurrently. When generating
+// deterministic output, the functions are instead invoked one at a time in order.
+func (i *Indexer) run(ch <-chan func()) (*sync.WaitGroup, *uint64) {
+	if i.generationOptions.Deterministic {
+		return parallel.RunN(ch, 1)
+	}
+
+	return parallel.Run(ch)
+}
@@ -0,0 +1,21 @@
+package lsif
+
+// CallHierarchyCall is an edge from the result set of a function to the result set of a function
+// that it calls. The call sites are ranges of the given document, which contains the body of the
+// caller. Dynamic calls are calls of an interface method that may dispatch to the callee, which
+// implements the method. Incoming calls of a function are the calls whose inV is its result set.
+type CallHierarchyCall struct {
+	Edge
+	FromRanges []uint64 `json:"fromRanges"`
+	Document   uint64   `json:"document"`
+	Dynamic    bool     `json:"dynamic,omitempty"`
+}
+
+func NewCallHierarchyCall(id, outV, inV uint64, fromRanges []uint64, document uint64, dynamic bool) CallHierarchyCall {
+	return CallHierarchyCall{
+		Edge:       newEdge(id, outV, inV, "callHierarchy/call"),
+		FromRanges: fromRanges,
+		Document:   document,
+		Dynamic:    dynamic,
+	}
+}
@@ -0,0 +1,37 @@
+package lsif
+
+// DiagnosticSeverity is the severity of an LSP diagnostic.
+type DiagnosticSeverity int
+
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// Diagnostic is an LSP diagnostic, such as a compiler error.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Code     string             `json:"code,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// DiagnosticResult is a vertex holding the diagnostics of a document.
+type DiagnosticResult struct {
+	Element
+	Result []Diagnostic `json:"result"`
+}
+
+func NewDiagnosticResult(id uint64, result []Diagnostic) DiagnosticResult {
+	return DiagnosticResult{Element: newVertex(id, "diagnosticResult"), Result: result}
+}
+
+// TextDocumentDiagnostic is an edge from a document to its diagnostic result.
+type TextDocumentDiagnostic struct{ Edge }
+
+func NewTextDocumentDiagnostic(id, outV, inV uint64) TextDocumentDiagnostic {
+	return TextDocumentDiagnostic{newEdge(id, outV, inV, "textDocument/diagnostic")}
+}
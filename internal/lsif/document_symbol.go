@@ -0,0 +1,43 @@
+package lsif
+
+// SymbolKind is the kind of an LSP document symbol. Only the kinds of Go declarations are
+// defined.
+type SymbolKind int
+
+const (
+	SymbolKindClass     SymbolKind = 5
+	SymbolKindMethod    SymbolKind = 6
+	SymbolKindField     SymbolKind = 8
+	SymbolKindInterface SymbolKind = 11
+	SymbolKindFunction  SymbolKind = 12
+	SymbolKindVariable  SymbolKind = 13
+	SymbolKindConstant  SymbolKind = 14
+	SymbolKindStruct    SymbolKind = 23
+)
+
+// DocumentSymbol is an LSP document symbol. The range spans the entire declaration of the
+// symbol, and the selection range spans its name.
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// DocumentSymbolResult is a vertex holding the outline of a document.
+type DocumentSymbolResult struct {
+	Element
+	Result []DocumentSymbol `json:"result"`
+}
+
+func NewDocumentSymbolResult(id uint64, result []DocumentSymbol) DocumentSymbolResult {
+	return DocumentSymbolResult{Element: newVertex(id, "documentSymbolResult"), Result: result}
+}
+
+// TextDocumentDocumentSymbol is an edge from a document to its document symbol result.
+type TextDocumentDocumentSymbol struct{ Edge }
+
+func NewTextDocumentDocumentSymbol(id, outV, inV uint64) TextDocumentDocumentSymbol {
+	return TextDocumentDocumentSymbol{newEdge(id, outV, inV, "textDocument/documentSymbol")}
+}
@@ -0,0 +1,35 @@
+package lsif
+
+// FoldingRangeKind is the kind of an LSP folding range. Ranges of code have no kind.
+type FoldingRangeKind string
+
+const (
+	FoldingRangeComment FoldingRangeKind = "comment"
+	FoldingRangeImports FoldingRangeKind = "imports"
+)
+
+// FoldingRange is an LSP folding range. Lines are 0-indexed and inclusive. The range of a
+// delimited construct ends on the line before its closing delimiter, so that the delimiter
+// remains visible when the range is folded.
+type FoldingRange struct {
+	StartLine int              `json:"startLine"`
+	EndLine   int              `json:"endLine"`
+	Kind      FoldingRangeKind `json:"kind,omitempty"`
+}
+
+// FoldingRangeResult is a vertex holding the folding ranges of a document.
+type FoldingRangeResult struct {
+	Element
+	Result []FoldingRange `json:"result"`
+}
+
+func NewFoldingRangeResult(id uint64, result []FoldingRange) FoldingRangeResult {
+	return FoldingRangeResult{Element: newVertex(id, "foldingRangeResult"), Result: result}
+}
+
+// TextDocumentFoldingRange is an edge from a document to its folding range result.
+type TextDocumentFoldingRange struct{ Edge }
+
+func NewTextDocumentFoldingRange(id, outV, inV uint64) TextDocumentFoldingRange {
+	return TextDocumentFoldingRange{newEdge(id, outV, inV, "textDocument/foldingRange")}
+}
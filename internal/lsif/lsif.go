@@ -0,0 +1,34 @@
+// Package lsif defines the LSIF elements emitted by lsif-go that are not part of the protocol
+// package of the Sourcegraph library. Like the types of that package, each element marshals
+// to the JSON representation of the LSIF specification and has a constructor taking its ID.
+package lsif
+
+import protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+
+// Element is the header shared by all vertices and edges.
+type Element struct {
+	ID    uint64 `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// Edge is the header of an edge between two vertices.
+type Edge struct {
+	Element
+	OutV uint64 `json:"outV"`
+	InV  uint64 `json:"inV"`
+}
+
+func newVertex(id uint64, label string) Element {
+	return Element{ID: id, Type: "vertex", Label: label}
+}
+
+func newEdge(id, outV, inV uint64, label string) Edge {
+	return Edge{Element: Element{ID: id, Type: "edge", Label: label}, OutV: outV, InV: inV}
+}
+
+// Range is an LSP range within a document (0-indexed).
+type Range struct {
+	Start protocol.Pos `json:"start"`
+	End   protocol.Pos `json:"end"`
+}
@@ -0,0 +1,18 @@
+package lsif
+
+// MonikerSymbolKind is a vertex recording the kind of the object named by a moniker. Output
+// formats such as SCIP distinguish types, methods, and terms in the name of each symbol, which
+// the identifier of the moniker alone does not. It is not part of LSIF dumps.
+type MonikerSymbolKind struct {
+	Element
+	Moniker uint64     `json:"moniker"`
+	Kind    SymbolKind `json:"kind"`
+}
+
+func NewMonikerSymbolKind(id, moniker uint64, kind SymbolKind) MonikerSymbolKind {
+	return MonikerSymbolKind{
+		Element: newVertex(id, "monikerSymbolKind"),
+		Moniker: moniker,
+		Kind:    kind,
+	}
+}
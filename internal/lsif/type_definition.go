@@ -0,0 +1,17 @@
+package lsif
+
+// TypeDefinitionResult is a vertex whose items are the ranges defining the type of a symbol.
+type TypeDefinitionResult struct {
+	Element
+}
+
+func NewTypeDefinitionResult(id uint64) TypeDefinitionResult {
+	return TypeDefinitionResult{Element: newVertex(id, "typeDefinitionResult")}
+}
+
+// TextDocumentTypeDefinition is an edge from a range or result set to its type definition result.
+type TextDocumentTypeDefinition struct{ Edge }
+
+func NewTextDocumentTypeDefinition(id, outV, inV uint64) TextDocumentTypeDefinition {
+	return TextDocumentTypeDefinition{newEdge(id, outV, inV, "textDocument/typeDefinition")}
+}
@@ -0,0 +1,19 @@
+package lsif
+
+// TypeHierarchySupertype is an edge from the result set of a type to the result set of a type that
+// it embeds, either as an embedded field of a struct or as an embedded interface. The embedding is a
+// range of the given document, which contains the declaration of the subtype, and is omitted when the
+// subtype is defined in a dependency. Subtypes of a type are the edges whose inV is its result set.
+type TypeHierarchySupertype struct {
+	Edge
+	Range    uint64 `json:"range,omitempty"`
+	Document uint64 `json:"document,omitempty"`
+}
+
+func NewTypeHierarchySupertype(id, outV, inV, rangeID, document uint64) TypeHierarchySupertype {
+	return TypeHierarchySupertype{
+		Edge:     newEdge(id, outV, inV, "typeHierarchy/supertype"),
+		Range:    rangeID,
+		Document: document,
+	}
+}
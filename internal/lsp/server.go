@@ -0,0 +1,202 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position protocol.Pos `json:"position"`
+}
+
+type location struct {
+	URI   string             `json:"uri"`
+	Range protocol.RangeData `json:"range"`
+}
+
+type hover struct {
+	Contents interface{}        `json:"contents"`
+	Range    protocol.RangeData `json:"range"`
+}
+
+// Server is a language server that answers requests from the graph of a dump rather than
+// from source. It speaks JSON-RPC with the LSP base protocol framing over a single stream.
+type Server struct {
+	graph *dump.Graph
+}
+
+// NewServer creates a new server backed by the given graph.
+func NewServer(graph *dump.Graph) *Server {
+	return &Server{graph: graph}
+}
+
+// Serve reads requests from r and writes responses to w until the client sends the exit
+// notification or closes the stream.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+
+	for {
+		content, err := readMessage(reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		var request message
+		if err := json.Unmarshal(content, &request); err != nil {
+			if err := writeMessage(w, message{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if request.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(request.Method, request.Params)
+		if request.ID == nil {
+			// Notifications (e.g. initialized, textDocument/didOpen) do not get a response
+			continue
+		}
+
+		response := message{JSONRPC: "2.0", ID: request.ID, Error: rpcErr}
+		if rpcErr == nil {
+			if response.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+
+		if err := writeMessage(w, response); err != nil {
+			return err
+		}
+	}
+}
+
+// handle returns the result of the given method.
+func (s *Server) handle(method string, rawParams json.RawMessage) (interface{}, *responseError) {
+	switch method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"hoverProvider":          true,
+				"implementationProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "lsif-go"},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/definition", "textDocument/references", "textDocument/implementation", "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		uri, pos := params.TextDocument.URI, params.Position
+
+		switch method {
+		case "textDocument/definition":
+			return toLocations(s.graph.Definitions(uri, pos)), nil
+		case "textDocument/references":
+			return toLocations(s.graph.References(uri, pos)), nil
+		case "textDocument/implementation":
+			return toLocations(s.graph.Implementations(uri, pos)), nil
+		}
+
+		contents, r, ok := s.graph.Hover(uri, pos)
+		if !ok {
+			return nil, nil
+		}
+
+		return hover{Contents: contents, Range: r}, nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", method)}
+}
+
+func toLocations(locations []dump.Location) []location {
+	converted := make([]location, 0, len(locations))
+	for _, l := range locations {
+		converted = append(converted, location{URI: l.URI, Range: l.Range})
+	}
+
+	return converted
+}
+
+// readMessage reads the content of the next message. Each message is preceded by a set of
+// headers, of which only Content-Length is interpreted.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(headers) == 0 {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read headers: %v", err)
+	}
+
+	contentLength, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %v", err)
+	}
+
+	content := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, fmt.Errorf("failed to read message: %v", err)
+	}
+
+	return content, nil
+}
+
+// writeMessage writes the given message preceded by its Content-Length header.
+func writeMessage(w io.Writer, m message) error {
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(content), content); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/indexer"
+	"github.com/sourcegraph/lsif-go/internal/output"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+// testGraph returns a graph of a single document defining Foo at 2:5 and referencing it at 6:1.
+func testGraph(t *testing.T) *dump.Graph {
+	var buf bytes.Buffer
+	e := writer.NewEmitter(writer.NewJSONWriter(&buf))
+
+	documentID := e.EmitDocument("go", "/dev/repo/main.go")
+	definitionRangeID := e.EmitRange(protocol.Pos{Line: 2, Character: 5}, protocol.Pos{Line: 2, Character: 8})
+	referenceRangeID := e.EmitRange(protocol.Pos{Line: 6, Character: 1}, protocol.Pos{Line: 6, Character: 4})
+	resultSetID := e.EmitResultSet()
+	_ = e.EmitNext(definitionRangeID, resultSetID)
+	_ = e.EmitNext(referenceRangeID, resultSetID)
+	definitionResultID := e.EmitDefinitionResult()
+	_ = e.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+	_ = e.EmitItem(definitionResultID, []uint64{definitionRangeID}, documentID)
+	referenceResultID := e.EmitReferenceResult()
+	_ = e.EmitTextDocumentReferences(resultSetID, referenceResultID)
+	_ = e.EmitItemOfReferences(referenceResultID, []uint64{definitionRangeID, referenceRangeID}, documentID)
+	hoverResultID := e.EmitHoverResult(protocol.NewMarkupContent("```go\nfunc Foo()\n```", protocol.Markdown))
+	_ = e.EmitTextDocumentHover(resultSetID, hoverResultID)
+	_ = e.EmitContains(documentID, []uint64{definitionRangeID, referenceRangeID})
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing emitter: %s", err)
+	}
+
+	elements, err := dump.Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	return dump.NewGraph(elements)
+}
+
+func frame(t *testing.T, m map[string]interface{}) string {
+	content, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling request: %s", err)
+	}
+
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func positionParams(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": character},
+	}
+}
+
+// serve runs a server over the given requests, followed by the exit notification, and
+// returns the result (or error code) of each response keyed by request ID.
+func serve(t *testing.T, graph *dump.Graph, requests ...map[string]interface{}) map[string]string {
+	var in bytes.Buffer
+	for _, m := range append(requests, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}) {
+		in.WriteString(frame(t, m))
+	}
+
+	var out bytes.Buffer
+	if err := NewServer(graph).Serve(&in, &out); err != nil {
+		t.Fatalf("unexpected error serving: %s", err)
+	}
+
+	responses := map[string]string{}
+	reader := bufio.NewReader(&out)
+	for {
+		content, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+
+		var response message
+		if err := json.Unmarshal(content, &response); err != nil {
+			t.Fatalf("unexpected error unmarshalling response: %s", err)
+		}
+
+		if response.Error != nil {
+			responses[string(response.ID)] = fmt.Sprintf("error %d", response.Error.Code)
+		} else {
+			responses[string(response.ID)] = string(response.Result)
+		}
+	}
+
+	return responses
+}
+
+func TestServe(t *testing.T) {
+	responses := serve(
+		t,
+		testGraph(t),
+		map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "textDocument/definition", "params": positionParams("file:///dev/repo/main.go", 6, 2)},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "textDocument/references", "params": positionParams("file:///dev/repo/main.go", 2, 5)},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 4, "method": "textDocument/hover", "params": positionParams("file:///dev/repo/main.go", 6, 1)},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 5, "method": "textDocument/implementation", "params": positionParams("file:///dev/repo/main.go", 6, 1)},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 6, "method": "textDocument/hover", "params": positionParams("file:///dev/repo/main.go", 4, 0)},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 7, "method": "workspace/symbol", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 8, "method": "shutdown"},
+	)
+
+	definition := `{"uri":"file:///dev/repo/main.go","range":{"start":{"line":2,"character":5},"end":{"line":2,"character":8}}}`
+	reference := `{"uri":"file:///dev/repo/main.go","range":{"start":{"line":6,"character":1},"end":{"line":6,"character":4}}}`
+
+	expected := map[string]string{
+		"1": `{"capabilities":{"definitionProvider":true,"hoverProvider":true,"implementationProvider":true,"referencesProvider":true},"serverInfo":{"name":"lsif-go"}}`,
+		"2": "[" + definition + "]",
+		"3": "[" + definition + "," + reference + "]",
+		"4": `{"contents":{"kind":"markdown","value":"` + "```go\\nfunc Foo()\\n```" + `"},"range":{"start":{"line":6,"character":1},"end":{"line":6,"character":4}}}`,
+		"5": "[]",
+		"6": "null",
+		"7": fmt.Sprintf("error %d", codeMethodNotFound),
+		"8": "null",
+	}
+	if diff := cmp.Diff(expected, responses); diff != "" {
+		t.Errorf("unexpected responses (-want +got): %s", diff)
+	}
+}
+
+// TestServeFixtures answers requests from a dump of the indexer fixtures, which exercises
+// hover text and reference linking end-to-end through a serialized dump.
+func TestServeFixtures(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error getting working directory: %s", err)
+	}
+	projectRoot := filepath.Join(wd, "../testdata/fixtures")
+
+	var buf bytes.Buffer
+	i := indexer.New(
+		"/dev/github.com/sourcegraph/lsif-go/internal/testdata/fixtures",
+		"github.com/sourcegraph/lsif-go",
+		projectRoot,
+		protocol.ToolInfo{Name: "lsif-go", Version: "dev"},
+		"testdata",
+		"0.0.1",
+		map[string]gomod.GoModule{
+			"github.com/sourcegraph/lsif-go": {Name: "github.com/sourcegraph/lsif-go", Version: "dev"},
+			"github.com/golang/go":           {Name: "github.com/golang/go", Version: "go1.16"},
+		},
+		[]string{"std"},
+		indexer.NewJSONEmitter(writer.NewJSONWriter(&buf)),
+		indexer.NewPackageDataCache(),
+		output.Options{},
+		indexer.NewGenerationOptions(),
+	)
+	if err := i.Index(); err != nil {
+		t.Fatalf("unexpected error indexing testdata: %s", err)
+	}
+
+	elements, err := dump.Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+
+	parallelURI := "file://" + filepath.Join(projectRoot, "parallel.go")
+	responses := serve(
+		t,
+		dump.NewGraph(elements),
+		// errs in `errs <- err`
+		map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "textDocument/definition", "params": positionParams(parallelURI, 21, 3)},
+		// wg in `wg.Wait()`
+		map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "textDocument/references", "params": positionParams(parallelURI, 26, 1)},
+		// func Parallel
+		map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "textDocument/hover", "params": positionParams(parallelURI, 13, 5)},
+	)
+
+	var definitions []location
+	if err := json.Unmarshal([]byte(responses["1"]), &definitions); err != nil {
+		t.Fatalf("unexpected definition response %q: %s", responses["1"], err)
+	}
+	expectedDefinitions := []location{{URI: parallelURI, Range: protocol.RangeData{Start: protocol.Pos{Line: 15, Character: 1}, End: protocol.Pos{Line: 15, Character: 5}}}}
+	if diff := cmp.Diff(expectedDefinitions, definitions); diff != "" {
+		t.Errorf("unexpected definitions (-want +got): %s", diff)
+	}
+
+	var references []location
+	if err := json.Unmarshal([]byte(responses["2"]), &references); err != nil {
+		t.Fatalf("unexpected references response %q: %s", responses["2"], err)
+	}
+	if len(references) != 4 {
+		t.Errorf("incorrect reference count. want=%d have=%d", 4, len(references))
+	}
+
+	var h struct {
+		Contents protocol.MarkupContent `json:"contents"`
+	}
+	if err := json.Unmarshal([]byte(responses["3"]), &h); err != nil {
+		t.Fatalf("unexpected hover response %q: %s", responses["3"], err)
+	}
+	if expected := "func Parallel(ctx Context, fns ...ParallelizableFunc) error"; h.Contents.Kind != protocol.Markdown || !strings.Contains(h.Contents.Value, expected) {
+		t.Errorf("unexpected hover text. want markdown containing %q, have %+v", expected, h.Contents)
+	}
+}
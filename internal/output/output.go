@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sync"
@@ -43,8 +44,19 @@ var ticker = pentimento.NewAnimatedString([]string{
 // var failurePrefix = "✗"
 var successPrefix = "✔"
 
+// out is the destination of all non-animated progress output.
+var out io.Writer = os.Stdout
+
 // logger is used to log at the level -vv and above from multiple goroutines.
-var logger = log.New(os.Stdout, "", 0)
+var logger = log.New(out, "", 0)
+
+// SetOutput sets the destination of progress output. This is stdout by default, and
+// should be set to stderr when stdout is reserved for the dump itself. Animated output
+// is always drawn to stdout and should be disabled in that case.
+func SetOutput(w io.Writer) {
+	out = w
+	logger.SetOutput(w)
+}
 
 // WithProgress prints a spinner while the given function is active.
 func WithProgress(name string, fn func(), outputOptions Options) {
@@ -56,7 +68,7 @@ func WithProgress(name string, fn func(), outputOptions Options) {
 	WithProgressParallel(wg, name, outputOptions, count, 1)
 }
 
-// WithProgressParallel will continuously print progress to stdout until the given wait group
+// WithProgressParallel will continuously print progress until the given wait group
 // counter goes to zero. Progress is determined by the values of `c` (number of tasks completed)
 // and the value `n` (total number of tasks).
 func WithProgressParallel(wg *sync.WaitGroup, name string, outputOptions Options, c *uint64, n uint64) {
@@ -93,18 +105,18 @@ func withTitle(name string, outputOptions Options, fn func(printer *pentimento.P
 // withTitleStatic invokes the given function with non-animated output.
 func withTitleStatic(name string, verbosity Verbosity, fn func(printer *pentimento.Printer)) {
 	start := time.Now()
-	fmt.Printf("%s\n", name)
+	fmt.Fprintf(out, "%s\n", name)
 	fn(nil)
 
 	if verbosity > DefaultOutput {
-		fmt.Printf("Finished in %s.\n\n", util.HumanElapsed(start))
+		fmt.Fprintf(out, "Finished in %s.\n\n", util.HumanElapsed(start))
 	}
 }
 
 // withTitleStatic invokes the given function with animated output.
 func withTitleAnimated(name string, verbosity Verbosity, fn func(printer *pentimento.Printer)) {
 	start := time.Now()
-	fmt.Printf("%s %s... ", ticker, name)
+	fmt.Fprintf(out, "%s %s... ", ticker, name)
 
 	_ = pentimento.PrintProgress(func(printer *pentimento.Printer) error {
 		defer func() {
@@ -116,9 +128,9 @@ func withTitleAnimated(name string, verbosity Verbosity, fn func(printer *pentim
 	})
 
 	if verbosity > DefaultOutput {
-		fmt.Printf("%s %s... Done (%s)\n", successPrefix, name, util.HumanElapsed(start))
+		fmt.Fprintf(out, "%s %s... Done (%s)\n", successPrefix, name, util.HumanElapsed(start))
 	} else {
-		fmt.Printf("%s %s... Done\n", successPrefix, name)
+		fmt.Fprintf(out, "%s %s... Done\n", successPrefix, name)
 	}
 }
 
@@ -11,10 +11,16 @@ import (
 // values are written, and a pointer to the number of tasks that have completed, which is
 // updated atomically.
 func Run(ch <-chan func()) (*sync.WaitGroup, *uint64) {
+	return RunN(ch, runtime.GOMAXPROCS(0))
+}
+
+// RunN is like Run, but invokes the functions from at most n goroutines. When n is one, the
+// functions are invoked one at a time in the order they are read from the channel.
+func RunN(ch <-chan func(), n int) (*sync.WaitGroup, *uint64) {
 	var count uint64
 	var wg sync.WaitGroup
 
-	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+	for i := 0; i < n; i++ {
 		wg.Add(1)
 
 		go func() {
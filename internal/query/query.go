@@ -0,0 +1,122 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// Kind is the type of information requested for a position.
+type Kind string
+
+const (
+	Definition Kind = "definition"
+	References Kind = "references"
+	Hover      Kind = "hover"
+	Monikers   Kind = "monikers"
+)
+
+// ParsePosition parses a position of the form path/to/file.go:LINE:COL. The line and
+// column are 1-based, as in compiler output, and are returned as a 0-based position.
+func ParsePosition(s string) (string, protocol.Pos, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 {
+		return "", protocol.Pos{}, fmt.Errorf("malformed position %q: expected path:LINE:COL", s)
+	}
+
+	path := strings.Join(parts[:len(parts)-2], ":")
+	line, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil || line < 1 {
+		return "", protocol.Pos{}, fmt.Errorf("malformed line in position %q", s)
+	}
+	character, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || character < 1 {
+		return "", protocol.Pos{}, fmt.Errorf("malformed column in position %q", s)
+	}
+
+	return path, protocol.Pos{Line: line - 1, Character: character - 1}, nil
+}
+
+// Run answers the query of the given kind for the position (path/to/file.go:LINE:COL)
+// and writes the result to w, one entry per line. Locations are printed in the same
+// form as the position, relative to the project root of the dump where possible.
+func Run(g *dump.Graph, kind Kind, position string, w io.Writer) error {
+	path, pos, err := ParsePosition(position)
+	if err != nil {
+		return err
+	}
+
+	uri, err := resolveURI(g, path)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case Definition:
+		writeLocations(g, g.Definitions(uri, pos), w)
+	case References:
+		writeLocations(g, g.References(uri, pos), w)
+
+	case Hover:
+		contents, _, ok := g.Hover(uri, pos)
+		if ok {
+			text, err := dump.HoverText(contents)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w, text)
+		}
+
+	case Monikers:
+		for _, m := range g.Monikers(uri, pos) {
+			if m.PackageName != "" {
+				fmt.Fprintf(w, "%s %s %s (%s@%s)\n", m.Kind, m.Scheme, m.Identifier, m.PackageName, m.PackageVersion)
+			} else {
+				fmt.Fprintf(w, "%s %s %s\n", m.Kind, m.Scheme, m.Identifier)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown query %q", kind)
+	}
+
+	return nil
+}
+
+// resolveURI returns the URI of the document in the dump for the given path. Relative
+// paths are tried against the working directory and then against the project root of
+// the dump, so that a dump can be queried from outside of the indexed checkout.
+func resolveURI(g *dump.Graph, path string) (string, error) {
+	var candidates []string
+	if filepath.IsAbs(path) {
+		candidates = append(candidates, path)
+	} else {
+		if wd, err := os.Getwd(); err == nil {
+			candidates = append(candidates, filepath.Join(wd, path))
+		}
+		if root := strings.TrimPrefix(g.ProjectRoot(), "file://"); root != "" {
+			candidates = append(candidates, filepath.Join(root, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if uri := "file://" + candidate; g.HasDocument(uri) {
+			return uri, nil
+		}
+	}
+
+	return "", fmt.Errorf("no document for %s in dump", path)
+}
+
+func writeLocations(g *dump.Graph, locations []dump.Location, w io.Writer) {
+	for _, l := range locations {
+		fmt.Fprintf(w, "%s:%d:%d\n", g.RelativePath(l.URI), l.Range.Start.Line+1, l.Range.Start.Character+1)
+	}
+}
@@ -0,0 +1,137 @@
+package query
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/dump"
+	"github.com/sourcegraph/lsif-go/internal/gomod"
+	"github.com/sourcegraph/lsif-go/internal/indexer"
+	"github.com/sourcegraph/lsif-go/internal/output"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+func TestParsePosition(t *testing.T) {
+	testCases := []struct {
+		position     string
+		expectedPath string
+		expectedPos  protocol.Pos
+		expectedErr  bool
+	}{
+		{position: "parallel.go:14:6", expectedPath: "parallel.go", expectedPos: protocol.Pos{Line: 13, Character: 5}},
+		{position: "/a/b/c.go:1:1", expectedPath: "/a/b/c.go", expectedPos: protocol.Pos{Line: 0, Character: 0}},
+		{position: `C:\a\c.go:2:3`, expectedPath: `C:\a\c.go`, expectedPos: protocol.Pos{Line: 1, Character: 2}},
+		{position: "parallel.go:14", expectedErr: true},
+		{position: "parallel.go:x:6", expectedErr: true},
+		{position: "parallel.go:0:6", expectedErr: true},
+	}
+
+	for _, testCase := range testCases {
+		path, pos, err := ParsePosition(testCase.position)
+		if testCase.expectedErr {
+			if err == nil {
+				t.Errorf("expected error parsing %q", testCase.position)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", testCase.position, err)
+			continue
+		}
+		if path != testCase.expectedPath || pos != testCase.expectedPos {
+			t.Errorf("unexpected result for %q. want=%s %v have=%s %v", testCase.position, testCase.expectedPath, testCase.expectedPos, path, pos)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error getting working directory: %s", err)
+	}
+	projectRoot := filepath.Join(wd, "../testdata/fixtures")
+
+	var buf bytes.Buffer
+	i := indexer.New(
+		projectRoot,
+		"github.com/sourcegraph/lsif-go",
+		projectRoot,
+		protocol.ToolInfo{Name: "lsif-go", Version: "dev"},
+		"testdata",
+		"0.0.1",
+		map[string]gomod.GoModule{
+			"github.com/sourcegraph/lsif-go": {Name: "github.com/sourcegraph/lsif-go", Version: "dev"},
+			"github.com/golang/go":           {Name: "github.com/golang/go", Version: "go1.16"},
+		},
+		[]string{"std"},
+		indexer.NewJSONEmitter(writer.NewJSONWriter(&buf)),
+		indexer.NewPackageDataCache(),
+		output.Options{},
+		indexer.NewGenerationOptions(),
+	)
+	if err := i.Index(); err != nil {
+		t.Fatalf("unexpected error indexing testdata: %s", err)
+	}
+
+	elements, err := dump.Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading dump: %s", err)
+	}
+	g := dump.NewGraph(elements)
+
+	run := func(t *testing.T, kind Kind, position string) string {
+		var out bytes.Buffer
+		if err := Run(g, kind, position, &out); err != nil {
+			t.Fatalf("unexpected error running query: %s", err)
+		}
+
+		return out.String()
+	}
+
+	t.Run("definition", func(t *testing.T) {
+		// errs in `errs <- err`
+		if diff := cmp.Diff("parallel.go:16:2\n", run(t, Definition, "parallel.go:22:4")); diff != "" {
+			t.Errorf("unexpected definitions (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		// wg in `wg.Wait()`
+		expected := strings.Join([]string{
+			"parallel.go:15:6", // var wg sync.WaitGroup
+			"parallel.go:19:3", // wg.Add(1)
+			"parallel.go:23:4", // wg.Done()
+			"parallel.go:27:2", // wg.Wait()
+		}, "\n") + "\n"
+
+		if diff := cmp.Diff(expected, run(t, References, "parallel.go:27:2")); diff != "" {
+			t.Errorf("unexpected references (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		expected := "func Parallel(ctx Context, fns ...ParallelizableFunc) error"
+		if value := run(t, Hover, "parallel.go:14:6"); !strings.Contains(value, expected) {
+			t.Errorf("unexpected hover text. want=%q have=%q", expected, value)
+		}
+	})
+
+	t.Run("monikers", func(t *testing.T) {
+		expected := "export gomod github.com/sourcegraph/lsif-go/internal/testdata/fixtures:Parallel (testdata@0.0.1)"
+		if value := run(t, Monikers, "parallel.go:14:6"); !strings.Contains(value, expected) {
+			t.Errorf("unexpected monikers. want=%q have=%q", expected, value)
+		}
+	})
+
+	t.Run("unknown document", func(t *testing.T) {
+		if err := Run(g, Definition, "missing.go:1:1", &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for unknown document")
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package scip
+
+// Protobuf wire types used by the SCIP schema.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// Marshal returns the protobuf wire encoding of the index.
+func (index *Index) Marshal() []byte {
+	var b []byte
+	b = appendMessage(b, 1, index.Metadata.marshal())
+	for _, document := range index.Documents {
+		b = appendMessage(b, 2, document.marshal())
+	}
+
+	return b
+}
+
+func (metadata Metadata) marshal() []byte {
+	var b []byte
+	b = appendMessage(b, 2, metadata.ToolInfo.marshal())
+	b = appendString(b, 3, metadata.ProjectRoot)
+	b = appendInt32(b, 4, int32(metadata.TextDocumentEncoding))
+	return b
+}
+
+func (toolInfo ToolInfo) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, toolInfo.Name)
+	b = appendString(b, 2, toolInfo.Version)
+	for _, arg := range toolInfo.Arguments {
+		b = appendRepeatedString(b, 3, arg)
+	}
+
+	return b
+}
+
+func (document Document) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, document.RelativePath)
+	for _, occurrence := range document.Occurrences {
+		b = appendMessage(b, 2, occurrence.marshal())
+	}
+	for _, symbol := range document.Symbols {
+		b = appendMessage(b, 3, symbol.marshal())
+	}
+	b = appendString(b, 4, document.Language)
+	return b
+}
+
+func (occurrence Occurrence) marshal() []byte {
+	var b []byte
+	b = appendPackedInt32(b, 1, occurrence.Range)
+	b = appendString(b, 2, occurrence.Symbol)
+	b = appendInt32(b, 3, occurrence.SymbolRoles)
+	return b
+}
+
+func (symbol SymbolInformation) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, symbol.Symbol)
+	for _, documentation := range symbol.Documentation {
+		b = appendRepeatedString(b, 3, documentation)
+	}
+	for _, relationship := range symbol.Relationships {
+		b = appendMessage(b, 4, relationship.marshal())
+	}
+
+	return b
+}
+
+func (relationship Relationship) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, relationship.Symbol)
+	b = appendBool(b, 2, relationship.IsReference)
+	b = appendBool(b, 3, relationship.IsImplementation)
+	b = appendBool(b, 4, relationship.IsTypeDefinition)
+	b = appendBool(b, 5, relationship.IsDefinition)
+	return b
+}
+
+// appendVarint appends the base-128 varint encoding of v.
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+
+	return append(b, byte(v))
+}
+
+// appendTag appends the key of a field with the given number and wire type.
+func appendTag(b []byte, field int, wireType int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// appendBytes appends a length-delimited field. Unlike the other helpers, this does
+// not omit empty values as it is also used for repeated fields and sub-messages.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendMessage appends an embedded message field.
+func appendMessage(b []byte, field int, v []byte) []byte {
+	return appendBytes(b, field, v)
+}
+
+// appendString appends a singular string field. Empty strings are omitted as per proto3.
+func appendString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+
+	return appendBytes(b, field, []byte(v))
+}
+
+// appendRepeatedString appends a single element of a repeated string field.
+func appendRepeatedString(b []byte, field int, v string) []byte {
+	return appendBytes(b, field, []byte(v))
+}
+
+// appendInt32 appends a singular int32 field. Zero values are omitted as per proto3.
+func appendInt32(b []byte, field int, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, uint64(int64(v)))
+}
+
+// appendBool appends a singular bool field. False values are omitted as per proto3.
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, 1)
+}
+
+// appendPackedInt32 appends a packed repeated int32 field.
+func appendPackedInt32(b []byte, field int, vs []int32) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+
+	var packed []byte
+	for _, v := range vs {
+		packed = appendVarint(packed, uint64(int64(v)))
+	}
+
+	return appendBytes(b, field, packed)
+}
@@ -0,0 +1,57 @@
+package scip
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	index := &Index{
+		Metadata: Metadata{
+			ToolInfo:             ToolInfo{Name: "lsif-go"},
+			TextDocumentEncoding: UTF8,
+		},
+		Documents: []Document{
+			{
+				RelativePath: "a.go",
+				Occurrences: []Occurrence{
+					{Range: []int32{1, 2, 3}, Symbol: "local 1", SymbolRoles: SymbolRoleDefinition},
+				},
+			},
+		},
+	}
+
+	expected := []byte{
+		// metadata (field 1)
+		0x0a, 0x0d,
+		/**/ 0x12, 0x09, 0x0a, 0x07, 'l', 's', 'i', 'f', '-', 'g', 'o', // tool_info.name
+		/**/ 0x20, 0x01, // text_document_encoding
+		// documents (field 2)
+		0x12, 0x18,
+		/**/ 0x0a, 0x04, 'a', '.', 'g', 'o', // relative_path
+		/**/ 0x12, 0x10, // occurrences
+		/*    */ 0x0a, 0x03, 0x01, 0x02, 0x03, // range
+		/*    */ 0x12, 0x07, 'l', 'o', 'c', 'a', 'l', ' ', '1', // symbol
+		/*    */ 0x18, 0x01, // symbol_roles
+	}
+
+	if encoded := index.Marshal(); !bytes.Equal(encoded, expected) {
+		t.Errorf("unexpected encoding.\nwant=%x\nhave=%x", expected, encoded)
+	}
+}
+
+func TestAppendVarint(t *testing.T) {
+	testCases := map[uint64][]byte{
+		0:   {0x00},
+		1:   {0x01},
+		127: {0x7f},
+		128: {0x80, 0x01},
+		300: {0xac, 0x02},
+	}
+
+	for value, expected := range testCases {
+		if encoded := appendVarint(nil, value); !bytes.Equal(encoded, expected) {
+			t.Errorf("unexpected encoding of %d. want=%x have=%x", value, expected, encoded)
+		}
+	}
+}
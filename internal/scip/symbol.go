@@ -0,0 +1,96 @@
+package scip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+)
+
+// symbolScheme is the scheme prefixing every global symbol emitted by lsif-go.
+const symbolScheme = "lsif-go"
+
+// formatSymbol converts a gomod moniker identifier of the form `{package}:{identifier}` and
+// the package information attached to the moniker into a global SCIP symbol. The package path
+// becomes a namespace descriptor and each dot-separated part of the identifier becomes a type,
+// method, or term descriptor depending on the kind of the named object (e.g. `pkg/Type#Method().`),
+// so that the symbol of an exported definition in one index matches the symbol of an import in
+// another in the same way the monikers they are derived from do.
+func formatSymbol(manager, name, version, identifier string, kind lsif.SymbolKind) string {
+	pkg, ident, _ := strings.Cut(identifier, ":")
+
+	descriptors := escapeDescriptorName(pkg) + "/"
+	if ident != "" {
+		parts := strings.Split(ident, ".")
+		for j, part := range parts {
+			descriptors += escapeDescriptorName(part) + descriptorSuffix(kind, j, len(parts))
+		}
+	}
+
+	return strings.Join([]string{
+		symbolScheme,
+		escapePackagePart(manager),
+		escapePackagePart(name),
+		escapePackagePart(version),
+		descriptors,
+	}, " ")
+}
+
+// descriptorSuffix returns the suffix of the descriptor of the given part of the identifier of an
+// object of the given kind. Methods and fields are qualified by the name of their type, and fields
+// of anonymous structs by the names of the enclosing fields. Parts of an object of unknown kind
+// are terms.
+func descriptorSuffix(kind lsif.SymbolKind, part, numParts int) string {
+	switch kind {
+	case lsif.SymbolKindClass, lsif.SymbolKindInterface, lsif.SymbolKindStruct:
+		return "#"
+
+	case lsif.SymbolKindFunction, lsif.SymbolKindMethod:
+		if part == numParts-1 {
+			return "()."
+		}
+
+		return "#"
+
+	case lsif.SymbolKindField:
+		if part == 0 && numParts > 1 {
+			return "#"
+		}
+	}
+
+	return "."
+}
+
+// formatLocalSymbol returns a document-local SCIP symbol for the given identifier.
+func formatLocalSymbol(id uint64) string {
+	return fmt.Sprintf("local %d", id)
+}
+
+// escapePackagePart escapes a space-delimited part of the package segment of a symbol.
+// Spaces are escaped by doubling them, and empty values are replaced by a single dot.
+func escapePackagePart(part string) string {
+	if part == "" {
+		return "."
+	}
+
+	return strings.ReplaceAll(part, " ", "  ")
+}
+
+// escapeDescriptorName returns the given name as-is if it consists only of identifier
+// characters, otherwise it is wrapped in backticks (with embedded backticks doubled).
+func escapeDescriptorName(name string) string {
+	for _, r := range name {
+		if !isSimpleIdentifierCharacter(r) {
+			return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+		}
+	}
+
+	return name
+}
+
+func isSimpleIdentifierCharacter(r rune) bool {
+	return r == '_' || r == '+' || r == '-' || r == '$' ||
+		('a' <= r && r <= 'z') ||
+		('A' <= r && r <= 'Z') ||
+		('0' <= r && r <= '9')
+}
@@ -0,0 +1,86 @@
+package scip
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+)
+
+func TestFormatSymbol(t *testing.T) {
+	testCases := []struct {
+		identifier string
+		kind       lsif.SymbolKind
+		expected   string
+	}{
+		{
+			identifier: "github.com/test/pkg",
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo",
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo.",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo.Bar",
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo.Bar.",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo",
+			kind:       lsif.SymbolKindStruct,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo#",
+		},
+		{
+			identifier: "github.com/test/pkg:Reader",
+			kind:       lsif.SymbolKindInterface,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Reader#",
+		},
+		{
+			identifier: "github.com/test/pkg:Duration",
+			kind:       lsif.SymbolKindClass,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Duration#",
+		},
+		{
+			identifier: "github.com/test/pkg:Run",
+			kind:       lsif.SymbolKindFunction,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Run().",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo.Bar",
+			kind:       lsif.SymbolKindMethod,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo#Bar().",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo.Bar",
+			kind:       lsif.SymbolKindField,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo#Bar.",
+		},
+		{
+			identifier: "github.com/test/pkg:Foo.Bar.Baz",
+			kind:       lsif.SymbolKindField,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Foo#Bar.Baz.",
+		},
+		{
+			identifier: "github.com/test/pkg:Version",
+			kind:       lsif.SymbolKindConstant,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Version.",
+		},
+		{
+			identifier: "github.com/test/pkg:Default",
+			kind:       lsif.SymbolKindVariable,
+			expected:   "lsif-go gomod github.com/test/pkg v1.2.3 `github.com/test/pkg`/Default.",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if symbol := formatSymbol("gomod", "github.com/test/pkg", "v1.2.3", testCase.identifier, testCase.kind); symbol != testCase.expected {
+			t.Errorf("unexpected symbol for %q (kind %d). want=%q have=%q", testCase.identifier, testCase.kind, testCase.expected, symbol)
+		}
+	}
+}
+
+func TestFormatSymbolEscaping(t *testing.T) {
+	expected := "lsif-go gomod my  module . `a``b`/c."
+	if symbol := formatSymbol("gomod", "my module", "", "a`b:c", 0); symbol != expected {
+		t.Errorf("unexpected symbol. want=%q have=%q", expected, symbol)
+	}
+}
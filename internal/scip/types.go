@@ -0,0 +1,72 @@
+package scip
+
+// The types in this file mirror the subset of the SCIP protobuf schema written by lsif-go.
+// See https://github.com/sourcegraph/scip/blob/main/scip.proto for the full schema.
+
+// Index is the top-level SCIP message.
+type Index struct {
+	Metadata  Metadata
+	Documents []Document
+}
+
+// Metadata describes the tool that produced the index and the project it describes.
+type Metadata struct {
+	ToolInfo             ToolInfo
+	ProjectRoot          string
+	TextDocumentEncoding TextEncoding
+}
+
+// ToolInfo describes the tool that produced the index.
+type ToolInfo struct {
+	Name      string
+	Version   string
+	Arguments []string
+}
+
+// TextEncoding is the encoding of the source files described by the index.
+type TextEncoding int32
+
+const (
+	UnspecifiedTextEncoding TextEncoding = 0
+	UTF8                    TextEncoding = 1
+	UTF16                   TextEncoding = 2
+)
+
+// Document contains the occurrences and symbols of a single source file.
+type Document struct {
+	Language     string
+	RelativePath string
+	Occurrences  []Occurrence
+	Symbols      []SymbolInformation
+}
+
+// Occurrence associates a source range with a symbol.
+type Occurrence struct {
+	// Range is [startLine, startCharacter, endLine, endCharacter], or the three-element
+	// form [startLine, startCharacter, endCharacter] when the range spans a single line.
+	Range       []int32
+	Symbol      string
+	SymbolRoles int32
+}
+
+// SymbolRole is a bitset describing the role of a symbol at an occurrence.
+const (
+	SymbolRoleDefinition int32 = 0x1
+	SymbolRoleImport     int32 = 0x2
+)
+
+// SymbolInformation holds metadata about a symbol defined in a document.
+type SymbolInformation struct {
+	Symbol        string
+	Documentation []string
+	Relationships []Relationship
+}
+
+// Relationship links a symbol to another symbol.
+type Relationship struct {
+	Symbol           string
+	IsReference      bool
+	IsImplementation bool
+	IsTypeDefinition bool
+	IsDefinition     bool
+}
@@ -0,0 +1,290 @@
+package scip
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+// Writer is a writer.JSONWriter that collects the LSIF elements written by the indexer's
+// emitter and, when flushed, writes the equivalent SCIP index to the underlying writer.
+// SCIP is not a streaming format, so the graph is held in memory until Flush is called.
+type Writer struct {
+	w           io.Writer
+	projectRoot string
+	toolInfo    protocol.ToolInfo
+
+	m                       sync.Mutex
+	documents               map[uint64]string           // documentID -> uri
+	ranges                  map[uint64]protocol.Range   // rangeID -> range
+	contains                map[uint64][]uint64         // documentID -> rangeIDs
+	next                    map[uint64]uint64           // rangeID or resultSetID -> resultSetID
+	definitions             map[uint64]uint64           // rangeID or resultSetID -> definitionResultID
+	items                   map[uint64][]uint64         // resultID -> rangeIDs
+	hovers                  map[uint64]uint64           // rangeID or resultSetID -> hoverResultID
+	hoverResults            map[uint64]string           // hoverResultID -> markdown
+	monikerEdges            map[uint64][]uint64         // rangeID or resultSetID -> monikerIDs
+	monikers                map[uint64]protocol.Moniker // monikerID -> moniker
+	monikerSymbolKinds      map[uint64]lsif.SymbolKind  // monikerID -> kind
+	packageInformationEdges map[uint64]uint64           // monikerID -> packageInformationID
+	packageInformation      map[uint64]protocol.PackageInformation
+}
+
+var _ writer.JSONWriter = &Writer{}
+
+// NewWriter creates a new Writer that will write a SCIP index to w. Document paths are
+// made relative to the given project root.
+func NewWriter(w io.Writer, projectRoot string, toolInfo protocol.ToolInfo) *Writer {
+	return &Writer{
+		w:                       w,
+		projectRoot:             projectRoot,
+		toolInfo:                toolInfo,
+		documents:               map[uint64]string{},
+		ranges:                  map[uint64]protocol.Range{},
+		contains:                map[uint64][]uint64{},
+		next:                    map[uint64]uint64{},
+		definitions:             map[uint64]uint64{},
+		items:                   map[uint64][]uint64{},
+		hovers:                  map[uint64]uint64{},
+		hoverResults:            map[uint64]string{},
+		monikerEdges:            map[uint64][]uint64{},
+		monikers:                map[uint64]protocol.Moniker{},
+		monikerSymbolKinds:      map[uint64]lsif.SymbolKind{},
+		packageInformationEdges: map[uint64]uint64{},
+		packageInformation:      map[uint64]protocol.PackageInformation{},
+	}
+}
+
+// Write records the given LSIF element. Elements that have no SCIP equivalent are dropped.
+func (w *Writer) Write(v interface{}) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	switch e := v.(type) {
+	case protocol.Document:
+		w.documents[e.ID] = e.URI
+	case protocol.Range:
+		w.ranges[e.ID] = e
+	case protocol.Contains:
+		w.contains[e.OutV] = append(w.contains[e.OutV], e.InVs...)
+	case protocol.Next:
+		w.next[e.OutV] = e.InV
+	case protocol.TextDocumentDefinition:
+		w.definitions[e.OutV] = e.InV
+	case protocol.Item:
+		w.items[e.OutV] = append(w.items[e.OutV], e.InVs...)
+	case protocol.TextDocumentHover:
+		w.hovers[e.OutV] = e.InV
+	case protocol.HoverResult:
+		if contents, ok := e.Result.Contents.(protocol.MarkupContent); ok {
+			w.hoverResults[e.ID] = contents.Value
+		}
+	case protocol.MonikerEdge:
+		w.monikerEdges[e.OutV] = append(w.monikerEdges[e.OutV], e.InV)
+	case protocol.Moniker:
+		w.monikers[e.ID] = e
+	case lsif.MonikerSymbolKind:
+		w.monikerSymbolKinds[e.Moniker] = e.Kind
+	case protocol.PackageInformationEdge:
+		w.packageInformationEdges[e.OutV] = e.InV
+	case protocol.PackageInformation:
+		w.packageInformation[e.ID] = e
+	}
+}
+
+// Flush converts the collected graph into a SCIP index and writes it to the underlying writer.
+func (w *Writer) Flush() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	_, err := w.w.Write(w.index().Marshal())
+	return err
+}
+
+// index converts the collected LSIF graph into a SCIP index.
+func (w *Writer) index() *Index {
+	definitionRanges := map[uint64]struct{}{}
+	for _, definitionResultID := range w.definitions {
+		for _, rangeID := range w.items[definitionResultID] {
+			definitionRanges[rangeID] = struct{}{}
+		}
+	}
+
+	documentIDs := make([]uint64, 0, len(w.documents))
+	for documentID := range w.documents {
+		documentIDs = append(documentIDs, documentID)
+	}
+	sort.Slice(documentIDs, func(i, j int) bool {
+		return w.documents[documentIDs[i]] < w.documents[documentIDs[j]]
+	})
+
+	prefix := "file://" + strings.TrimSuffix(w.projectRoot, "/") + "/"
+
+	documents := make([]Document, 0, len(documentIDs))
+	for _, documentID := range documentIDs {
+		documents = append(documents, w.document(strings.TrimPrefix(w.documents[documentID], prefix), w.contains[documentID], definitionRanges))
+	}
+
+	return &Index{
+		Metadata: Metadata{
+			ToolInfo: ToolInfo{
+				Name:      w.toolInfo.Name,
+				Version:   w.toolInfo.Version,
+				Arguments: w.toolInfo.Args,
+			},
+			ProjectRoot:          "file://" + w.projectRoot,
+			TextDocumentEncoding: UTF8,
+		},
+		Documents: documents,
+	}
+}
+
+// document converts the given ranges of a single LSIF document into a SCIP document.
+func (w *Writer) document(relativePath string, rangeIDs []uint64, definitionRanges map[uint64]struct{}) Document {
+	var occurrences []Occurrence
+	var symbols []SymbolInformation
+	defined := map[string]struct{}{}
+
+	for _, rangeID := range rangeIDs {
+		r, ok := w.ranges[rangeID]
+		if !ok {
+			continue
+		}
+
+		resultSetID, ok := w.next[rangeID]
+		if !ok {
+			continue
+		}
+		symbol := w.symbol(rangeID, resultSetID)
+
+		if _, ok := definitionRanges[rangeID]; ok {
+			definedSymbol := w.resultSetSymbol(resultSetID)
+			occurrences = append(occurrences, Occurrence{Range: scipRange(r), Symbol: definedSymbol, SymbolRoles: SymbolRoleDefinition})
+
+			if _, ok := defined[definedSymbol]; !ok {
+				defined[definedSymbol] = struct{}{}
+				symbols = append(symbols, w.symbolInformation(resultSetID, definedSymbol))
+			}
+
+			// An import moniker attached directly to a definition range (e.g. an embedded field of
+			// a type declared in a dependency) is a reference to the imported symbol.
+			if symbol != definedSymbol {
+				occurrences = append(occurrences, Occurrence{Range: scipRange(r), Symbol: symbol})
+			}
+		} else {
+			occurrences = append(occurrences, Occurrence{Range: scipRange(r), Symbol: symbol})
+		}
+
+		// A definition result attached directly to a range overrides the one of its result set.
+		// This happens for ranges that both define and reference a symbol (e.g. embedded fields),
+		// in which case we emit an additional reference occurrence for the referenced symbol.
+		if definitionResultID, ok := w.definitions[rangeID]; ok {
+			for _, targetRangeID := range w.items[definitionResultID] {
+				if targetResultSetID, ok := w.next[targetRangeID]; ok {
+					if targetSymbol := w.symbol(targetRangeID, targetResultSetID); targetSymbol != symbol {
+						occurrences = append(occurrences, Occurrence{Range: scipRange(r), Symbol: targetSymbol})
+					}
+				}
+
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return compareRanges(occurrences[i].Range, occurrences[j].Range) < 0
+	})
+
+	return Document{
+		Language:     "go",
+		RelativePath: relativePath,
+		Occurrences:  occurrences,
+		Symbols:      symbols,
+	}
+}
+
+// symbol returns the SCIP symbol for the given range and its result set. An import moniker
+// attached directly to the range takes precedence over the monikers of the result set.
+func (w *Writer) symbol(rangeID, resultSetID uint64) string {
+	if symbol, ok := w.monikerSymbol(rangeID); ok {
+		return symbol
+	}
+
+	return w.resultSetSymbol(resultSetID)
+}
+
+// resultSetSymbol returns the SCIP symbol for the given result set. Result sets with an import or
+// export moniker receive a global symbol derived from that moniker; all others are local.
+func (w *Writer) resultSetSymbol(resultSetID uint64) string {
+	if symbol, ok := w.monikerSymbol(resultSetID); ok {
+		return symbol
+	}
+
+	return formatLocalSymbol(resultSetID)
+}
+
+// monikerSymbol returns the global SCIP symbol derived from the first import or export moniker
+// attached to the given range or result set, if any.
+func (w *Writer) monikerSymbol(id uint64) (string, bool) {
+	for _, monikerID := range w.monikerEdges[id] {
+		moniker, ok := w.monikers[monikerID]
+		if !ok || (moniker.Kind != "import" && moniker.Kind != "export") {
+			continue
+		}
+
+		packageInformation, ok := w.packageInformation[w.packageInformationEdges[monikerID]]
+		if !ok {
+			continue
+		}
+
+		return formatSymbol(moniker.Scheme, packageInformation.Name, packageInformation.Version, moniker.Identifier, w.monikerSymbolKinds[monikerID]), true
+	}
+
+	return "", false
+}
+
+// symbolInformation returns the symbol information for the given defined result set.
+func (w *Writer) symbolInformation(resultSetID uint64, symbol string) SymbolInformation {
+	info := SymbolInformation{Symbol: symbol}
+	if hover, ok := w.hoverResults[w.hovers[resultSetID]]; ok && hover != "" {
+		info.Documentation = []string{hover}
+	}
+
+	return info
+}
+
+// scipRange converts an LSIF range into the compact SCIP range representation.
+func scipRange(r protocol.Range) []int32 {
+	if r.Start.Line == r.End.Line {
+		return []int32{int32(r.Start.Line), int32(r.Start.Character), int32(r.End.Character)}
+	}
+
+	return []int32{int32(r.Start.Line), int32(r.Start.Character), int32(r.End.Line), int32(r.End.Character)}
+}
+
+// compareRanges orders SCIP ranges by their start position, then by their end position.
+func compareRanges(a, b []int32) int {
+	boundsA, boundsB := rangeBounds(a), rangeBounds(b)
+	for i := range boundsA {
+		if boundsA[i] != boundsB[i] {
+			return int(boundsA[i] - boundsB[i])
+		}
+	}
+
+	return 0
+}
+
+// rangeBounds returns the start line, start character, end line, and end character of the
+// given SCIP range.
+func rangeBounds(r []int32) [4]int32 {
+	if len(r) == 3 {
+		return [4]int32{r[0], r[1], r[0], r[2]}
+	}
+
+	return [4]int32{r[0], r[1], r[2], r[3]}
+}
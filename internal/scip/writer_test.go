@@ -0,0 +1,118 @@
+package scip
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+func TestWriter(t *testing.T) {
+	w := NewWriter(nil, "/dev/repo", protocol.ToolInfo{Name: "lsif-go", Version: "dev"})
+	emitter := writer.NewEmitter(w)
+
+	documentID := emitter.EmitDocument("go", "/dev/repo/main.go")
+
+	// func Exported() {}
+	exportedRangeID := emitter.EmitRange(protocol.Pos{Line: 2, Character: 5}, protocol.Pos{Line: 2, Character: 13})
+	exportedResultSetID := emitter.EmitResultSet()
+	exportedDefinitionResultID := emitter.EmitDefinitionResult()
+	_ = emitter.EmitNext(exportedRangeID, exportedResultSetID)
+	_ = emitter.EmitTextDocumentDefinition(exportedResultSetID, exportedDefinitionResultID)
+	_ = emitter.EmitItem(exportedDefinitionResultID, []uint64{exportedRangeID}, documentID)
+	_ = emitter.EmitTextDocumentHover(exportedResultSetID, emitter.EmitHoverResult(protocol.NewMarkupContent("docs", protocol.Markdown)))
+	packageInformationID := emitter.EmitPackageInformation("github.com/test/repo", "gomod", "v1.0.0")
+	exportMonikerID := emitter.EmitMoniker("export", "gomod", "github.com/test/repo:Exported")
+	_ = emitter.EmitPackageInformationEdge(exportMonikerID, packageInformationID)
+	_ = emitter.EmitMonikerEdge(exportedResultSetID, exportMonikerID)
+	w.Write(lsif.NewMonikerSymbolKind(0, exportMonikerID, lsif.SymbolKindFunction))
+
+	// x := 1
+	localRangeID := emitter.EmitRange(protocol.Pos{Line: 3, Character: 1}, protocol.Pos{Line: 3, Character: 2})
+	localResultSetID := emitter.EmitResultSet()
+	localDefinitionResultID := emitter.EmitDefinitionResult()
+	_ = emitter.EmitNext(localRangeID, localResultSetID)
+	_ = emitter.EmitTextDocumentDefinition(localResultSetID, localDefinitionResultID)
+	_ = emitter.EmitItem(localDefinitionResultID, []uint64{localRangeID}, documentID)
+
+	// Exported()
+	referenceRangeID := emitter.EmitRange(protocol.Pos{Line: 4, Character: 1}, protocol.Pos{Line: 4, Character: 9})
+	_ = emitter.EmitNext(referenceRangeID, exportedResultSetID)
+
+	// type T struct{ io.Reader }
+	embeddedRangeID := emitter.EmitRange(protocol.Pos{Line: 5, Character: 19}, protocol.Pos{Line: 5, Character: 25})
+	embeddedResultSetID := emitter.EmitResultSet()
+	embeddedDefinitionResultID := emitter.EmitDefinitionResult()
+	_ = emitter.EmitNext(embeddedRangeID, embeddedResultSetID)
+	_ = emitter.EmitTextDocumentDefinition(embeddedResultSetID, embeddedDefinitionResultID)
+	_ = emitter.EmitItem(embeddedDefinitionResultID, []uint64{embeddedRangeID}, documentID)
+	stdPackageInformationID := emitter.EmitPackageInformation("github.com/golang/go", "gomod", "go1.18")
+	importMonikerID := emitter.EmitMoniker("import", "gomod", "github.com/golang/go/std/io:Reader")
+	_ = emitter.EmitPackageInformationEdge(importMonikerID, stdPackageInformationID)
+	_ = emitter.EmitMonikerEdge(embeddedRangeID, importMonikerID)
+	w.Write(lsif.NewMonikerSymbolKind(0, importMonikerID, lsif.SymbolKindInterface))
+
+	_ = emitter.EmitContains(documentID, []uint64{referenceRangeID, localRangeID, exportedRangeID, embeddedRangeID})
+
+	exportedSymbol := "lsif-go gomod github.com/test/repo v1.0.0 `github.com/test/repo`/Exported()."
+	localSymbol := formatLocalSymbol(localResultSetID)
+	embeddedSymbol := formatLocalSymbol(embeddedResultSetID)
+	readerSymbol := "lsif-go gomod github.com/golang/go go1.18 `github.com/golang/go/std/io`/Reader#"
+
+	expected := &Index{
+		Metadata: Metadata{
+			ToolInfo:             ToolInfo{Name: "lsif-go", Version: "dev"},
+			ProjectRoot:          "file:///dev/repo",
+			TextDocumentEncoding: UTF8,
+		},
+		Documents: []Document{
+			{
+				Language:     "go",
+				RelativePath: "main.go",
+				Occurrences: []Occurrence{
+					{Range: []int32{2, 5, 13}, Symbol: exportedSymbol, SymbolRoles: SymbolRoleDefinition},
+					{Range: []int32{3, 1, 2}, Symbol: localSymbol, SymbolRoles: SymbolRoleDefinition},
+					{Range: []int32{4, 1, 9}, Symbol: exportedSymbol},
+					{Range: []int32{5, 19, 25}, Symbol: embeddedSymbol, SymbolRoles: SymbolRoleDefinition},
+					{Range: []int32{5, 19, 25}, Symbol: readerSymbol},
+				},
+				Symbols: []SymbolInformation{
+					{Symbol: localSymbol},
+					{Symbol: exportedSymbol, Documentation: []string{"docs"}},
+					{Symbol: embeddedSymbol},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, w.index()); diff != "" {
+		t.Errorf("unexpected index (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareRanges(t *testing.T) {
+	ranges := [][]int32{
+		{4, 1, 9},
+		{2, 5, 3, 1},
+		{2, 5, 13},
+		{2, 5, 2},
+		{2, 1, 4},
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return compareRanges(ranges[i], ranges[j]) < 0
+	})
+
+	expected := [][]int32{
+		{2, 1, 4},
+		{2, 5, 2},
+		{2, 5, 13},
+		{2, 5, 3, 1},
+		{4, 1, 9},
+	}
+	if diff := cmp.Diff(expected, ranges); diff != "" {
+		t.Errorf("unexpected ranges (-want +got):\n%s", diff)
+	}
+}
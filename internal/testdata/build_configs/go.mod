@@ -0,0 +1,3 @@
+module github.com/sourcegraph/lsif-go/internal/testdata/build_configs
+
+go 1.18
@@ -0,0 +1,6 @@
+//go:build linux
+
+package buildconfigs
+
+// Open opens a file on Linux.
+func Open() Handle { return Shared() }
@@ -0,0 +1,6 @@
+//go:build windows
+
+package buildconfigs
+
+// Open opens a file on Windows.
+func Open() Handle { return Shared() }
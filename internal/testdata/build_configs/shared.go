@@ -0,0 +1,7 @@
+package buildconfigs
+
+// Handle is an open file handle.
+type Handle struct{ fd uintptr }
+
+// Shared is declared for every platform.
+func Shared() Handle { return Handle{} }
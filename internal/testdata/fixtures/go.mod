@@ -1,3 +1,3 @@
 module github.com/sourcegraph/lsif-go/internal/testdata/fixtures
 
-go 1.16
+go 1.18
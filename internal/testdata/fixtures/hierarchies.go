@@ -0,0 +1,55 @@
+package testdata
+
+import "io"
+
+// Shape is a closed figure.
+type Shape interface {
+	Perimeter() int
+}
+
+// Polygon is a shape with straight sides.
+type Polygon interface {
+	Shape
+	Sides() int
+}
+
+// Square is a polygon with four sides of the same length.
+type Square struct {
+	Side int
+}
+
+func (s Square) Perimeter() int { return s.Sides() * s.Side }
+
+func (Square) Sides() int { return 4 }
+
+// Tile is a square with a texture.
+type Tile struct {
+	Square
+	io.Reader
+}
+
+// Measure returns the perimeter of the given polygon.
+func Measure(p Polygon) int {
+	return p.Perimeter()
+}
+
+// DefaultTile is a tile with sides of length one.
+var DefaultTile = Tile{Square: Square{Side: 1}}
+
+// Stack is a stack of items of the same type.
+type Stack[T any] struct {
+	items []T
+}
+
+// Push adds the given item to the top of the stack.
+func (s *Stack[T]) Push(item T) { s.items = append(s.items, item) }
+
+// StackTiles returns a stack of the given tiles.
+func StackTiles(tiles ...Tile) *Stack[Tile] {
+	s := &Stack[Tile]{}
+	for _, tile := range tiles {
+		s.Push(tile)
+	}
+
+	return s
+}
@@ -0,0 +1,9 @@
+package testdata
+
+import "io"
+
+// Board lists the tiles at each corner of a board.
+var Board map[string][]*Tile
+
+// Texture is the reader of the texture of a tile.
+var Texture io.Reader
@@ -0,0 +1,219 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sourcegraph/lsif-go/internal/lsif"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+// Error describes a single violated invariant of an LSIF graph.
+type Error struct {
+	ID      uint64 // identifier of the offending vertex or edge
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("element %d: %s", e.ID, e.Message)
+}
+
+// edge is the label-independent shape of an LSIF edge.
+type edge struct {
+	id       uint64
+	label    string
+	outV     uint64
+	inVs     []uint64
+	document uint64 // only set for item edges
+}
+
+// Validate checks the invariants of the LSIF graph formed by the given elements, which are
+// the protocol values written by the indexer's emitter (or read back from a dump). The
+// following invariants are checked:
+//
+//   - every edge refers only to emitted vertices
+//   - every range is contained in a document
+//   - every range of an item edge is contained in the document named by the edge
+//   - every non-local moniker is linked to package information
+//   - every range is linked to at most one result set
+//
+// The returned errors are ordered by the identifier of the offending element.
+func Validate(elements []interface{}) []Error {
+	vertices := map[uint64]struct{}{}
+	ranges := map[uint64]struct{}{}
+	documents := map[uint64]struct{}{}
+	monikers := map[uint64]protocol.Moniker{}
+	var edges []edge
+
+	for _, element := range elements {
+		if id, ok := vertexID(element); ok {
+			vertices[id] = struct{}{}
+
+			switch v := element.(type) {
+			case protocol.Range:
+				ranges[v.ID] = struct{}{}
+			case protocol.Document:
+				documents[v.ID] = struct{}{}
+			case protocol.Moniker:
+				monikers[v.ID] = v
+			}
+		} else if e, ok := toEdge(element); ok {
+			edges = append(edges, e)
+		}
+	}
+
+	var errs []Error
+	rangeDocuments := map[uint64]uint64{}       // rangeID -> documentID
+	resultSets := map[uint64][]uint64{}         // rangeID -> resultSetIDs
+	packageInformation := map[uint64]struct{}{} // set of monikerIDs with package information
+
+	for _, e := range edges {
+		for _, id := range append([]uint64{e.outV}, e.inVs...) {
+			if _, ok := vertices[id]; !ok {
+				errs = append(errs, Error{ID: e.id, Message: fmt.Sprintf("%s edge refers to unknown vertex %d", e.label, id)})
+			}
+		}
+
+		switch e.label {
+		case "contains":
+			if _, ok := documents[e.outV]; ok {
+				for _, inV := range e.inVs {
+					rangeDocuments[inV] = e.outV
+				}
+			}
+
+		case "item":
+			if _, ok := vertices[e.document]; !ok {
+				errs = append(errs, Error{ID: e.id, Message: fmt.Sprintf("item edge refers to unknown document %d", e.document)})
+			}
+
+		case "next":
+			if _, ok := ranges[e.outV]; ok {
+				resultSets[e.outV] = append(resultSets[e.outV], e.inVs...)
+			}
+
+		case "packageInformation":
+			packageInformation[e.outV] = struct{}{}
+		}
+	}
+
+	for _, e := range edges {
+		if e.label != "item" {
+			continue
+		}
+
+		for _, inV := range e.inVs {
+			if _, ok := ranges[inV]; !ok {
+				continue
+			}
+
+			if documentID, ok := rangeDocuments[inV]; ok && documentID != e.document {
+				errs = append(errs, Error{ID: e.id, Message: fmt.Sprintf("item edge places range %d in document %d, but it is contained in document %d", inV, e.document, documentID)})
+			}
+		}
+	}
+
+	for rangeID := range ranges {
+		if _, ok := rangeDocuments[rangeID]; !ok {
+			errs = append(errs, Error{ID: rangeID, Message: "range is not contained in any document"})
+		}
+
+		if n := len(resultSets[rangeID]); n > 1 {
+			errs = append(errs, Error{ID: rangeID, Message: fmt.Sprintf("range is linked to %d result sets %v", n, resultSets[rangeID])})
+		}
+	}
+
+	for monikerID, moniker := range monikers {
+		if _, ok := packageInformation[monikerID]; !ok && moniker.Kind != "local" {
+			errs = append(errs, Error{ID: monikerID, Message: fmt.Sprintf("%s moniker %q has no package information", moniker.Kind, moniker.Identifier)})
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].ID != errs[j].ID {
+			return errs[i].ID < errs[j].ID
+		}
+
+		return errs[i].Message < errs[j].Message
+	})
+
+	return errs
+}
+
+// vertexID returns the identifier of the given element if it is a vertex.
+func vertexID(element interface{}) (uint64, bool) {
+	switch v := element.(type) {
+	case protocol.MetaData:
+		return v.ID, true
+	case protocol.Project:
+		return v.ID, true
+	case protocol.Document:
+		return v.ID, true
+	case protocol.Range:
+		return v.ID, true
+	case protocol.ResultSet:
+		return v.ID, true
+	case protocol.HoverResult:
+		return v.ID, true
+	case protocol.DefinitionResult:
+		return v.ID, true
+	case protocol.ReferenceResult:
+		return v.ID, true
+	case protocol.ImplementationResult:
+		return v.ID, true
+	case lsif.TypeDefinitionResult:
+		return v.ID, true
+	case protocol.Moniker:
+		return v.ID, true
+	case protocol.PackageInformation:
+		return v.ID, true
+	case lsif.DiagnosticResult:
+		return v.ID, true
+	case lsif.DocumentSymbolResult:
+		return v.ID, true
+	case lsif.FoldingRangeResult:
+		return v.ID, true
+	}
+
+	return 0, false
+}
+
+// toEdge converts the given element into its label-independent shape if it is an edge.
+func toEdge(element interface{}) (edge, bool) {
+	switch e := element.(type) {
+	case protocol.Contains:
+		return edge{id: e.ID, label: "contains", outV: e.OutV, inVs: e.InVs}, true
+	case protocol.Item:
+		return edge{id: e.ID, label: "item", outV: e.OutV, inVs: e.InVs, document: e.Document}, true
+	case protocol.Next:
+		return edge{id: e.ID, label: "next", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.TextDocumentHover:
+		return edge{id: e.ID, label: "textDocument/hover", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.TextDocumentDefinition:
+		return edge{id: e.ID, label: "textDocument/definition", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.TextDocumentReferences:
+		return edge{id: e.ID, label: "textDocument/references", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.TextDocumentImplementation:
+		return edge{id: e.ID, label: "textDocument/implementation", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.TextDocumentTypeDefinition:
+		return edge{id: e.ID, label: "textDocument/typeDefinition", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.MonikerEdge:
+		return edge{id: e.ID, label: "moniker", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.NextMonikerEdge:
+		return edge{id: e.ID, label: "nextMoniker", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case protocol.PackageInformationEdge:
+		return edge{id: e.ID, label: "packageInformation", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.TextDocumentDiagnostic:
+		return edge{id: e.ID, label: "textDocument/diagnostic", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.TextDocumentDocumentSymbol:
+		return edge{id: e.ID, label: "textDocument/documentSymbol", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.TextDocumentFoldingRange:
+		return edge{id: e.ID, label: "textDocument/foldingRange", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.CallHierarchyCall:
+		return edge{id: e.ID, label: "callHierarchy/call", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	case lsif.TypeHierarchySupertype:
+		return edge{id: e.ID, label: "typeHierarchy/supertype", outV: e.OutV, inVs: []uint64{e.InV}}, true
+	}
+
+	return edge{}, false
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+)
+
+type capturingWriter struct {
+	elements []interface{}
+}
+
+func (w *capturingWriter) Write(v interface{}) { w.elements = append(w.elements, v) }
+func (w *capturingWriter) Flush() error        { return nil }
+
+type graph struct {
+	documentID         uint64
+	definitionRangeID  uint64
+	referenceRangeID   uint64
+	resultSetID        uint64
+	definitionResultID uint64
+}
+
+// emitGraph emits a small valid graph with a single definition and reference.
+func emitGraph(e *writer.Emitter) graph {
+	projectID := e.EmitProject("go")
+	documentID := e.EmitDocument("go", "/dev/repo/main.go")
+	definitionRangeID := e.EmitRange(protocol.Pos{Line: 2, Character: 5}, protocol.Pos{Line: 2, Character: 8})
+	referenceRangeID := e.EmitRange(protocol.Pos{Line: 6, Character: 1}, protocol.Pos{Line: 6, Character: 4})
+	resultSetID := e.EmitResultSet()
+	_ = e.EmitNext(definitionRangeID, resultSetID)
+	_ = e.EmitNext(referenceRangeID, resultSetID)
+
+	definitionResultID := e.EmitDefinitionResult()
+	_ = e.EmitTextDocumentDefinition(resultSetID, definitionResultID)
+	_ = e.EmitItem(definitionResultID, []uint64{definitionRangeID}, documentID)
+
+	monikerID := e.EmitMoniker("export", "gomod", "github.com/test/repo:Foo")
+	packageInformationID := e.EmitPackageInformation("github.com/test/repo", "gomod", "v1.0.0")
+	_ = e.EmitPackageInformationEdge(monikerID, packageInformationID)
+	_ = e.EmitMonikerEdge(resultSetID, monikerID)
+
+	_ = e.EmitContains(documentID, []uint64{definitionRangeID, referenceRangeID})
+	_ = e.EmitContains(projectID, []uint64{documentID})
+
+	return graph{
+		documentID:         documentID,
+		definitionRangeID:  definitionRangeID,
+		referenceRangeID:   referenceRangeID,
+		resultSetID:        resultSetID,
+		definitionResultID: definitionResultID,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		emit     func(e *writer.Emitter, g graph)
+		expected []string
+	}{
+		{
+			name:     "valid",
+			emit:     func(e *writer.Emitter, g graph) {},
+			expected: nil,
+		},
+		{
+			name: "unknown vertex",
+			emit: func(e *writer.Emitter, g graph) {
+				_ = e.EmitTextDocumentHover(g.resultSetID, 1000)
+			},
+			expected: []string{"textDocument/hover edge refers to unknown vertex 1000"},
+		},
+		{
+			name: "range not contained",
+			emit: func(e *writer.Emitter, g graph) {
+				_ = e.EmitRange(protocol.Pos{Line: 8, Character: 1}, protocol.Pos{Line: 8, Character: 4})
+			},
+			expected: []string{"range is not contained in any document"},
+		},
+		{
+			name: "item document mismatch",
+			emit: func(e *writer.Emitter, g graph) {
+				otherDocumentID := e.EmitDocument("go", "/dev/repo/other.go")
+				referenceResultID := e.EmitReferenceResult()
+				_ = e.EmitTextDocumentReferences(g.resultSetID, referenceResultID)
+				_ = e.EmitItemOfReferences(referenceResultID, []uint64{g.referenceRangeID}, otherDocumentID)
+			},
+			expected: []string{"item edge places range 4 in document"},
+		},
+		{
+			name: "moniker without package information",
+			emit: func(e *writer.Emitter, g graph) {
+				monikerID := e.EmitMoniker("import", "gomod", "fmt:Println")
+				_ = e.EmitMonikerEdge(g.resultSetID, monikerID)
+			},
+			expected: []string{`import moniker "fmt:Println" has no package information`},
+		},
+		{
+			name: "duplicate result sets",
+			emit: func(e *writer.Emitter, g graph) {
+				_ = e.EmitNext(g.referenceRangeID, e.EmitResultSet())
+			},
+			expected: []string{"range is linked to 2 result sets"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			w := &capturingWriter{}
+			e := writer.NewEmitter(w)
+			testCase.emit(e, emitGraph(e))
+
+			errs := Validate(w.elements)
+			if len(errs) != len(testCase.expected) {
+				t.Fatalf("unexpected number of errors. want=%d have=%d (%v)", len(testCase.expected), len(errs), errs)
+			}
+
+			for i, expected := range testCase.expected {
+				if !strings.Contains(errs[i].Message, expected) {
+					t.Errorf("unexpected error. want=%q have=%q", expected, errs[i].Message)
+				}
+			}
+		})
+	}
+}